@@ -0,0 +1,76 @@
+package easyssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var errInMemoryAgent = errors.New("Agent forwarding is not possible in InMemory mode")
+
+// makes the local SSH agent available to sessions on client which request
+// agent forwarding. The agent connection is closed with the client.
+func (ssh_conf *MakeConfig) forwardAgent(client *ssh.Client) error {
+	if ssh_conf.InMemory {
+		return errInMemoryAgent
+	}
+
+	sshAgent, err := dialAgent()
+	if err != nil {
+		return fmt.Errorf("Error connecting to SSH agent: %s", err)
+	}
+
+	if err := agent.ForwardToAgent(client, agent.NewClient(sshAgent)); err != nil {
+		sshAgent.Close()
+		return fmt.Errorf("Error forwarding SSH agent: %s", err)
+	}
+
+	ssh_conf.spawn(func() {
+		client.Wait()
+		sshAgent.Close()
+	})
+
+	return nil
+}
+
+// onlyIdentities returns a function returning the signers of agentSigners
+// whose public key is the one of any of identities.
+func onlyIdentities(agentSigners func() ([]ssh.Signer, error), identities []ssh.Signer) func() ([]ssh.Signer, error) {
+	return func() ([]ssh.Signer, error) {
+		all, err := agentSigners()
+		if err != nil {
+			return nil, err
+		}
+
+		var signers []ssh.Signer
+		for _, signer := range all {
+			key := signer.PublicKey().Marshal()
+			for _, identity := range identities {
+				if bytes.Equal(key, identity.PublicKey().Marshal()) {
+					signers = append(signers, signer)
+					break
+				}
+			}
+		}
+		return signers, nil
+	}
+}
+
+// requests agent forwarding for session if ForwardAgent is set
+func (ssh_conf *MakeConfig) requestAgentForwarding(session *ssh.Session) error {
+	if !ssh_conf.ForwardAgent {
+		return nil
+	}
+
+	err := ssh_conf.request(session, func() error {
+		return agent.RequestAgentForwarding(session)
+	})
+	if err != nil {
+		return fmt.Errorf("Error requesting agent forwarding: %s", err)
+	}
+
+	return nil
+}
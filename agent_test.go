@@ -0,0 +1,71 @@
+package easyssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParsingForwardAgent(t *testing.T) {
+	cfg := `
+Host forwarded
+	ForwardAgent yes
+Host plain
+	ForwardAgent no
+`
+	for host, expected := range map[string]bool{"forwarded": true, "plain": false} {
+		result, err := parseClientConfig(strings.NewReader(cfg), host)
+		if err != nil {
+			t.Fatalf("Error parsing config: %s", err)
+		}
+		if result.ForwardAgent != expected {
+			t.Errorf("Expected ForwardAgent %v for %s, got %v", expected, host, result.ForwardAgent)
+		}
+	}
+}
+
+func TestForwardAgentInMemory(t *testing.T) {
+	cfg := &MakeConfig{InMemory: true, ForwardAgent: true}
+	if err := cfg.forwardAgent(nil); err != errInMemoryAgent {
+		t.Errorf("Expected errInMemoryAgent, got %v", err)
+	}
+}
+
+func TestParsingIdentitiesOnly(t *testing.T) {
+	cfg := `
+Host bastion
+	IdentitiesOnly yes
+	IdentityAgent none
+`
+	result, err := parseClientConfig(strings.NewReader(cfg), "bastion")
+	if err != nil {
+		t.Fatalf("Error parsing config: %s", err)
+	}
+	if !result.IdentitiesOnly || !result.NoAgent {
+		t.Errorf("Expected IdentitiesOnly and NoAgent to be set, got %v and %v", result.IdentitiesOnly, result.NoAgent)
+	}
+}
+
+func TestOnlyIdentities(t *testing.T) {
+	var keys []ssh.Signer
+	for i := 0; i < 3; i++ {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("Error generating key: %s", err)
+		}
+		signer, _ := ssh.NewSignerFromKey(priv)
+		keys = append(keys, signer)
+	}
+
+	agentSigners := func() ([]ssh.Signer, error) { return keys, nil }
+	signers, err := onlyIdentities(agentSigners, []ssh.Signer{keys[1]})()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signers) != 1 || signers[0] != keys[1] {
+		t.Errorf("Expected only the configured key to be offered, got %d keys", len(signers))
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package easyssh
+
+import (
+	"io"
+	"net"
+	"os"
+)
+
+// dialAgent connects to the SSH agent listening on SSH_AUTH_SOCK.
+func dialAgent() (io.ReadWriteCloser, error) {
+	return net.Dial("unix", os.Getenv("SSH_AUTH_SOCK"))
+}
@@ -0,0 +1,22 @@
+package easyssh
+
+import (
+	"io"
+	"os"
+)
+
+// windowsAgentPipe is the named pipe the Windows OpenSSH agent listens on.
+const windowsAgentPipe = `\\.\pipe\openssh-ssh-agent`
+
+// dialAgent connects to the SSH agent. On Windows, SSH_AUTH_SOCK may point to
+// the named pipe of an agent, otherwise the pipe of the Windows OpenSSH agent
+// service is used. Pageant is only reachable through its OpenSSH compatible
+// named pipe, its window message based protocol is not implemented.
+func dialAgent() (io.ReadWriteCloser, error) {
+	pipe := os.Getenv("SSH_AUTH_SOCK")
+	if pipe == "" {
+		pipe = windowsAgentPipe
+	}
+
+	return os.OpenFile(pipe, os.O_RDWR, 0)
+}
@@ -0,0 +1,102 @@
+package easyssh
+
+import (
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// enableLegacyAlgorithms allows config to negotiate all algorithms supported by
+// golang.org/x/crypto/ssh, including the insecure ones (ex. ssh-rsa with SHA-1
+// signatures, diffie-hellman-group14-sha1 or CBC mode ciphers) which are
+// disabled by default, but still needed to talk to old network gear.
+func enableLegacyAlgorithms(config *ssh.ClientConfig) {
+	supported := ssh.SupportedAlgorithms()
+	insecure := ssh.InsecureAlgorithms()
+
+	config.KeyExchanges = append(supported.KeyExchanges, insecure.KeyExchanges...)
+	config.Ciphers = append(supported.Ciphers, insecure.Ciphers...)
+	config.MACs = append(supported.MACs, insecure.MACs...)
+	config.HostKeyAlgorithms = append(supported.HostKeys, insecure.HostKeys...)
+}
+
+// FIPSAlgorithms returns the algorithms approved by FIPS 140, for use as
+// Algorithms of MakeConfig.
+func FIPSAlgorithms() ssh.Algorithms {
+	return ssh.Algorithms{
+		KeyExchanges: []string{
+			ssh.KeyExchangeECDHP256, ssh.KeyExchangeECDHP384, ssh.KeyExchangeECDHP521,
+			ssh.KeyExchangeDH14SHA256, ssh.KeyExchangeDH16SHA512,
+		},
+		Ciphers: []string{
+			ssh.CipherAES128GCM, ssh.CipherAES256GCM,
+			ssh.CipherAES128CTR, ssh.CipherAES192CTR, ssh.CipherAES256CTR,
+		},
+		MACs: []string{
+			ssh.HMACSHA256ETM, ssh.HMACSHA512ETM, ssh.HMACSHA256, ssh.HMACSHA512,
+		},
+		HostKeys: []string{
+			ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521,
+			ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSASHA512,
+		},
+	}
+}
+
+// applyAlgorithms restricts config to the algorithms listed in Algorithms of
+// MakeConfig. Empty lists keep the algorithms already configured.
+func (ssh_conf *MakeConfig) applyAlgorithms(config *ssh.ClientConfig) {
+	if len(ssh_conf.Algorithms.KeyExchanges) > 0 {
+		config.KeyExchanges = ssh_conf.Algorithms.KeyExchanges
+	}
+	if len(ssh_conf.Algorithms.Ciphers) > 0 {
+		config.Ciphers = ssh_conf.Algorithms.Ciphers
+	}
+	if len(ssh_conf.Algorithms.MACs) > 0 {
+		config.MACs = ssh_conf.Algorithms.MACs
+	}
+	if len(ssh_conf.Algorithms.HostKeys) > 0 {
+		config.HostKeyAlgorithms = ssh_conf.Algorithms.HostKeys
+	}
+}
+
+// parseAlgorithms parses an algorithm list of an ssh_config file. Like
+// OpenSSH, a list starting with "+" is appended to the defaults, one starting
+// with "-" is removed from them and one starting with "^" is put in front of
+// them.
+func parseAlgorithms(value string, defaults []string) []string {
+	if value == "" {
+		return nil
+	}
+
+	prefix := value[0]
+	if prefix == '+' || prefix == '-' || prefix == '^' {
+		value = value[1:]
+	}
+	list := strings.Split(value, ",")
+
+	switch prefix {
+	case '+':
+		return append(append([]string{}, defaults...), list...)
+	case '^':
+		return append(list, defaults...)
+	case '-':
+		var result []string
+		for _, algo := range defaults {
+			if !contains(list, algo) {
+				result = append(result, algo)
+			}
+		}
+		return result
+	}
+
+	return list
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package easyssh
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestEnableLegacyAlgorithms(t *testing.T) {
+	config := &ssh.ClientConfig{}
+	enableLegacyAlgorithms(config)
+
+	expected := map[string][]string{
+		"key exchange": {"diffie-hellman-group14-sha1", "curve25519-sha256"},
+		"cipher":       {"aes128-cbc", "aes128-gcm@openssh.com"},
+		"host key":     {"ssh-rsa", "ssh-ed25519"},
+	}
+	actual := map[string][]string{
+		"key exchange": config.KeyExchanges,
+		"cipher":       config.Ciphers,
+		"host key":     config.HostKeyAlgorithms,
+	}
+
+	for kind, algos := range expected {
+		for _, algo := range algos {
+			if !contains(actual[kind], algo) {
+				t.Errorf("Expected %s algorithm %s to be enabled, got %v", kind, algo, actual[kind])
+			}
+		}
+	}
+}
+
+func TestApplyAlgorithms(t *testing.T) {
+	config := &ssh.ClientConfig{}
+	cfg := &MakeConfig{Algorithms: FIPSAlgorithms()}
+	cfg.applyAlgorithms(config)
+
+	if contains(config.Ciphers, ssh.CipherChaCha20Poly1305) || !contains(config.Ciphers, ssh.CipherAES256GCM) {
+		t.Errorf("Expected FIPS ciphers only, got %v", config.Ciphers)
+	}
+	if contains(config.KeyExchanges, ssh.KeyExchangeCurve25519) {
+		t.Errorf("Expected FIPS key exchanges only, got %v", config.KeyExchanges)
+	}
+
+	config = &ssh.ClientConfig{}
+	enableLegacyAlgorithms(config)
+	cfg = &MakeConfig{Algorithms: ssh.Algorithms{Ciphers: []string{ssh.CipherAES128CTR}}}
+	cfg.applyAlgorithms(config)
+	if len(config.Ciphers) != 1 || !contains(config.HostKeyAlgorithms, ssh.KeyAlgoRSA) {
+		t.Errorf("Expected only the ciphers to be restricted, got %v and %v", config.Ciphers, config.HostKeyAlgorithms)
+	}
+}
+
+func TestParseAlgorithms(t *testing.T) {
+	defaults := []string{"a", "b", "c"}
+	tests := map[string][]string{
+		"x,y":  {"x", "y"},
+		"+x":   {"a", "b", "c", "x"},
+		"-b,c": {"a"},
+		"^x":   {"x", "a", "b", "c"},
+	}
+
+	for value, expected := range tests {
+		if result := parseAlgorithms(value, defaults); !reflect.DeepEqual(result, expected) {
+			t.Errorf("Expected %v for '%s', got %v", expected, value, result)
+		}
+	}
+	if !reflect.DeepEqual(defaults, []string{"a", "b", "c"}) {
+		t.Errorf("Expected defaults to be unchanged, got %v", defaults)
+	}
+}
+
+func TestParsingAlgorithms(t *testing.T) {
+	cfg := `
+Host switch
+	HostKeyAlgorithms +ssh-rsa
+	Ciphers aes128-ctr,aes256-ctr
+`
+	result, err := parseClientConfig(strings.NewReader(cfg), "switch")
+	if err != nil {
+		t.Fatalf("Error parsing config: %s", err)
+	}
+	if !contains(result.Algorithms.HostKeys, ssh.KeyAlgoRSA) || !contains(result.Algorithms.HostKeys, ssh.KeyAlgoED25519) {
+		t.Errorf("Expected ssh-rsa to be added to the host key algorithms, got %v", result.Algorithms.HostKeys)
+	}
+	if !reflect.DeepEqual(result.Algorithms.Ciphers, []string{"aes128-ctr", "aes256-ctr"}) {
+		t.Errorf("Expected ciphers to be set, got %v", result.Algorithms.Ciphers)
+	}
+}
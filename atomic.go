@@ -0,0 +1,41 @@
+package easyssh
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+)
+
+// tempName returns a hidden, random file name in the directory of target, so
+// the file can be renamed into place without crossing file systems. The name
+// is derived from Rand if set, so it is predictable in tests, and from
+// crypto/rand otherwise, so others cannot guess it.
+func (ssh_conf *MakeConfig) tempName(target string) string {
+	var suffix string
+	if ssh_conf.Rand != nil {
+		suffix = fmt.Sprintf("%012x", uint64(ssh_conf.Rand()*(1<<48)))
+	} else {
+		b := make([]byte, 6)
+		rand.Read(b)
+		suffix = hex.EncodeToString(b)
+	}
+	return path.Join(path.Dir(target), "."+path.Base(target)+"."+suffix+".tmp")
+}
+
+// replaceFile renames tmp to target on the remote machine. If backupSuffix is
+// set, an existing target is copied to target + backupSuffix first.
+func (ssh_conf *MakeConfig) replaceFile(tmp, target, backupSuffix string) error {
+	command := "mv -f -- " + shellQuote(tmp) + " " + shellQuote(target)
+	if backupSuffix != "" {
+		t := shellQuote(target)
+		command = "{ [ ! -e " + t + " ] || cp -p -- " + t + " " + shellQuote(target+backupSuffix) + "; } && " + command
+	}
+
+	_, stderr, err := ssh_conf.capture(command)
+	if err != nil {
+		ssh_conf.capture("rm -f -- " + shellQuote(tmp))
+		return remoteFileError("replace", target, stderr, err)
+	}
+	return nil
+}
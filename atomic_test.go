@@ -0,0 +1,57 @@
+package easyssh
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestTempName(t *testing.T) {
+	cfg := &MakeConfig{Rand: func() float64 { return 0.5 }}
+	if name := cfg.tempName("/etc/nginx/nginx.conf"); name != "/etc/nginx/.nginx.conf.800000000000.tmp" {
+		t.Errorf("Expected hidden temporary file next to target, got %s", name)
+	}
+	if name := cfg.tempName("motd"); name != ".motd.800000000000.tmp" {
+		t.Errorf("Expected relative temporary file name, got %s", name)
+	}
+
+	cfg = &MakeConfig{}
+	if cfg.tempName("motd") == cfg.tempName("motd") {
+		t.Errorf("Expected random temporary file names")
+	}
+}
+
+func TestAtomicUpload(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	cfg.Rand = func() float64 { return 0.25 }
+
+	tmp := "/.motd.400000000000.tmp"
+	srv.Handle("mv -f -- '"+tmp+"' '/motd'", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		content, err := srv.ReadFile(tmp)
+		if err != nil || srv.WriteFile("/motd", content) != nil {
+			return 1
+		}
+		return 0
+	})
+
+	src, err := ioutil.TempFile("", "easyssh")
+	if err != nil {
+		t.Fatalf("Error creating temp file: %s", err)
+	}
+	defer os.Remove(src.Name())
+	src.WriteString("Welcome\n")
+	src.Close()
+
+	if err := cfg.UploadWithOptions(src.Name(), "/motd", UploadOptions{Atomic: true}); err != nil {
+		t.Fatalf("Error uploading: %s", err)
+	}
+	if content, err := srv.ReadFile("/motd"); err != nil || string(content) != "Welcome\n" {
+		t.Errorf("Expected the file to be moved into place, got %q (%v)", content, err)
+	}
+	expected := []string{"scp -t '" + tmp + "'", "mv -f -- '" + tmp + "' '/motd'"}
+	if commands := srv.Commands(); len(commands) != 2 || commands[0] != expected[0] || commands[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, commands)
+	}
+}
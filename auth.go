@@ -0,0 +1,67 @@
+package easyssh
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AuthMethod names a way of authenticating to the server.
+type AuthMethod string
+
+// The authentication methods easyssh supports.
+const (
+	AuthPassword            AuthMethod = "password"
+	AuthKeyboardInteractive AuthMethod = "keyboard-interactive"
+	AuthPublicKey           AuthMethod = "publickey"
+	AuthAgent               AuthMethod = "agent"
+)
+
+// DefaultAuthMethods is the order authentication methods are tried in if
+// AuthMethods is not set.
+var DefaultAuthMethods = []AuthMethod{AuthPassword, AuthKeyboardInteractive, AuthPublicKey, AuthAgent}
+
+// defaultPasswordRetries is how often PasswordPrompt is asked if
+// PasswordRetries is not set, like OpenSSH's NumberOfPasswordPrompts.
+const defaultPasswordRetries = 3
+
+// returns the authentication methods to try in order, failing for unknown ones
+func (ssh_conf *MakeConfig) authMethods() ([]AuthMethod, error) {
+	if ssh_conf.AuthMethods == nil {
+		return DefaultAuthMethods, nil
+	}
+
+	for _, method := range ssh_conf.AuthMethods {
+		switch method {
+		case AuthPassword, AuthKeyboardInteractive, AuthPublicKey, AuthAgent:
+		default:
+			return nil, fmt.Errorf("Unknown authentication method '%s'", method)
+		}
+	}
+	return ssh_conf.AuthMethods, nil
+}
+
+// returns whether method is one of methods
+func hasAuthMethod(methods []AuthMethod, method AuthMethod) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// parses the value of PreferredAuthentications of ssh_config. Public key
+// authentication includes the agent, methods easyssh does not support (ex.
+// gssapi-with-mic) are skipped.
+func parsePreferredAuthentications(value string) []AuthMethod {
+	methods := []AuthMethod{}
+	for _, name := range strings.Split(value, ",") {
+		switch method := AuthMethod(strings.TrimSpace(name)); method {
+		case AuthPassword, AuthKeyboardInteractive:
+			methods = append(methods, method)
+		case AuthPublicKey:
+			methods = append(methods, AuthPublicKey, AuthAgent)
+		}
+	}
+	return methods
+}
@@ -0,0 +1,192 @@
+package easyssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
+)
+
+func TestAuthMethodOrder(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("Error creating signer: %s", err)
+	}
+
+	srv, err := testserver.New("test", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	srv.Authorize(signer.PublicKey())
+
+	for _, c := range []struct {
+		methods  []AuthMethod
+		expected AuthMethod
+	}{
+		{nil, AuthPassword},
+		{[]AuthMethod{AuthPublicKey, AuthPassword}, AuthPublicKey},
+		{[]AuthMethod{AuthAgent, AuthPassword}, AuthPassword},
+	} {
+		cfg := &MakeConfig{User: "test", Password: "secret", Server: srv.Host(), Port: srv.Port(), InMemory: true,
+			HostKeyCallback: ssh.FixedHostKey(srv.HostKey()), Signers: []ssh.Signer{signer}, AuthMethods: c.methods}
+		client, err := cfg.Connect()
+		if err != nil {
+			t.Errorf("Error connecting with %v: %s", c.methods, err)
+			continue
+		}
+		if client.AuthMethod() != c.expected {
+			t.Errorf("Expected %s to succeed with %v, got %s", c.expected, c.methods, client.AuthMethod())
+		}
+		client.Close()
+	}
+
+	cfg := &MakeConfig{User: "test", Password: "secret", Server: srv.Host(), Port: srv.Port(), InMemory: true,
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey()), AuthMethods: []AuthMethod{AuthPublicKey}}
+	if _, err := cfg.Run("true"); err == nil {
+		t.Errorf("Expected password authentication not to be used")
+	}
+}
+
+func TestUnknownAuthMethod(t *testing.T) {
+	cfg := &MakeConfig{User: "test", Server: "localhost", AuthMethods: []AuthMethod{"hostbased"}}
+	if _, err := cfg.Connect(); err == nil || !strings.Contains(err.Error(), "hostbased") {
+		t.Errorf("Expected error for unknown method, got %v", err)
+	}
+}
+
+func TestParsingPreferredAuthentications(t *testing.T) {
+	cfg := `
+Host legacy
+	PreferredAuthentications gssapi-with-mic,keyboard-interactive,publickey
+`
+	result, err := parseClientConfig(strings.NewReader(cfg), "legacy")
+	if err != nil {
+		t.Fatalf("Error parsing config: %s", err)
+	}
+	expected := []AuthMethod{AuthKeyboardInteractive, AuthPublicKey, AuthAgent}
+	if !reflect.DeepEqual(result.AuthMethods, expected) {
+		t.Errorf("Expected %v, got %v", expected, result.AuthMethods)
+	}
+}
+
+func TestPasswordPrompt(t *testing.T) {
+	srv, err := testserver.New("test", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	var attempts []int
+	prompt := func(attempt int) (string, error) {
+		attempts = append(attempts, attempt)
+		if attempt < 2 {
+			return "wrong", nil
+		}
+		return "secret", nil
+	}
+
+	cfg := &MakeConfig{User: "test", Server: srv.Host(), Port: srv.Port(), InMemory: true,
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey()), PasswordPrompt: prompt}
+	client, err := cfg.Connect()
+	if err != nil {
+		t.Fatalf("Error connecting: %s", err)
+	}
+	client.Close()
+	if !reflect.DeepEqual(attempts, []int{1, 2}) {
+		t.Errorf("Expected to be prompted twice, got %v", attempts)
+	}
+
+	attempts = nil
+	cfg.PasswordRetries = 1
+	if _, err := cfg.Connect(); err == nil {
+		t.Errorf("Expected connecting to fail after one attempt")
+	}
+	if !reflect.DeepEqual(attempts, []int{1}) {
+		t.Errorf("Expected to be prompted once, got %v", attempts)
+	}
+}
+
+func TestPasswordPromptNotNeeded(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("Error creating signer: %s", err)
+	}
+
+	srv, err := testserver.New("test", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	srv.Authorize(signer.PublicKey())
+
+	prompted := false
+	cfg := &MakeConfig{User: "test", Server: srv.Host(), Port: srv.Port(), InMemory: true,
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey()), Signers: []ssh.Signer{signer},
+		AuthMethods: []AuthMethod{AuthPublicKey, AuthPassword},
+		PasswordPrompt: func(int) (string, error) {
+			prompted = true
+			return "secret", nil
+		}}
+	client, err := cfg.Connect()
+	if err != nil {
+		t.Fatalf("Error connecting: %s", err)
+	}
+	client.Close()
+	if prompted {
+		t.Errorf("Expected not to be prompted for the password")
+	}
+}
+
+func TestChallengeCallback(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.SetChallenge([]string{"Password: ", "Verification code: "}, []string{"secret", "123456"})
+
+	var prompts []string
+	cfg.Password = ""
+	cfg.ChallengeCallback = func(name, instruction string, questions []string, echos []bool) ([]string, error) {
+		prompts = append(prompts, questions...)
+		return []string{"secret", "123456"}, nil
+	}
+
+	client, err := cfg.Connect()
+	if err != nil {
+		t.Fatalf("Error connecting: %s", err)
+	}
+	defer client.Close()
+
+	if client.AuthMethod() != AuthKeyboardInteractive {
+		t.Errorf("Expected keyboard-interactive authentication, got %s", client.AuthMethod())
+	}
+	if !reflect.DeepEqual(prompts, []string{"Password: ", "Verification code: "}) {
+		t.Errorf("Expected callback to receive the prompts, got %q", prompts)
+	}
+}
+
+func TestChallengeCallbackWrongAnswer(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.SetChallenge([]string{"Verification code: "}, []string{"123456"})
+
+	cfg.Password = ""
+	cfg.ChallengeCallback = func(name, instruction string, questions []string, echos []bool) ([]string, error) {
+		return []string{"000000"}, nil
+	}
+	if _, err := cfg.Connect(); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package easyssh
+
+import "sync"
+
+// Broadcaster passes every line of output of a command to all of its
+// subscribers (ex. a live view, a logger and a parser). Each subscriber has a
+// buffer of its own: if it is full, lines are dropped for this subscriber
+// only, so a slow subscriber never holds up the command or the others.
+// The last lines are kept to be replayed to new subscribers.
+type Broadcaster struct {
+	mu       sync.Mutex
+	subs     map[*Subscription]struct{}
+	replay   []string
+	capacity int
+	finished bool
+	done     chan struct{}
+}
+
+// Subscription receives the lines of a Broadcaster on Lines, which is closed
+// when the command is done or the subscription cancelled.
+type Subscription struct {
+	Lines <-chan string
+
+	lines       chan string
+	broadcaster *Broadcaster
+	dropped     int
+}
+
+// StreamBroadcast runs command like Stream and returns a Broadcaster for its
+// output, which keeps the last replay lines for new subscribers.
+func (ssh_conf *MakeConfig) StreamBroadcast(command string, replay int) (*Broadcaster, error) {
+	output, done, err := ssh_conf.Stream(command)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBroadcaster(ssh_conf.spawn, output, done, replay), nil
+}
+
+// newBroadcaster starts passing the lines from output to the subscribers using
+// a goroutine started by spawn until done.
+func newBroadcaster(spawn func(func()), output <-chan string, done <-chan bool, replay int) *Broadcaster {
+	b := &Broadcaster{
+		subs:     map[*Subscription]struct{}{},
+		capacity: replay,
+		done:     make(chan struct{}),
+	}
+
+	spawn(func() {
+		defer b.finish()
+		for {
+			select {
+			case <-done:
+				return
+			case line := <-output:
+				b.publish(line)
+			}
+		}
+	})
+
+	return b
+}
+
+// publish passes line to all subscribers and the replay buffer.
+func (b *Broadcaster) publish(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.capacity > 0 {
+		if len(b.replay) == b.capacity {
+			b.replay = b.replay[1:]
+		}
+		b.replay = append(b.replay, line)
+	}
+
+	for sub := range b.subs {
+		sub.send(line)
+	}
+}
+
+// finish closes all subscriptions once the command is done.
+func (b *Broadcaster) finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.finished = true
+	for sub := range b.subs {
+		close(sub.lines)
+	}
+	b.subs = nil
+	close(b.done)
+}
+
+// Subscribe returns a new subscription with room for buffer lines, which
+// first receives the lines kept for replay. If the command is done already,
+// Lines is closed after the replay.
+func (b *Broadcaster) Subscribe(buffer int) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lines := make(chan string, buffer+len(b.replay))
+	sub := &Subscription{Lines: lines, lines: lines, broadcaster: b}
+	for _, line := range b.replay {
+		sub.send(line)
+	}
+
+	if b.finished {
+		close(lines)
+	} else {
+		b.subs[sub] = struct{}{}
+	}
+	return sub
+}
+
+// Done is closed when the command is done and all lines are passed on.
+func (b *Broadcaster) Done() <-chan struct{} {
+	return b.done
+}
+
+// send passes line on without blocking. Callers need to hold the lock of the
+// Broadcaster.
+func (s *Subscription) send(line string) {
+	select {
+	case s.lines <- line:
+	default:
+		s.dropped++
+	}
+}
+
+// Dropped returns the number of lines dropped because the buffer of the
+// subscription was full.
+func (s *Subscription) Dropped() int {
+	s.broadcaster.mu.Lock()
+	defer s.broadcaster.mu.Unlock()
+	return s.dropped
+}
+
+// Cancel ends the subscription and closes Lines.
+func (s *Subscription) Cancel() {
+	b := s.broadcaster
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.lines)
+	}
+}
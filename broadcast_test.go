@@ -0,0 +1,48 @@
+package easyssh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func collect(lines <-chan string) []string {
+	var result []string
+	for line := range lines {
+		result = append(result, line)
+	}
+	return result
+}
+
+func TestBroadcaster(t *testing.T) {
+	cfg := &MakeConfig{}
+	output := make(chan string)
+	done := make(chan bool)
+	b := newBroadcaster(cfg.spawn, output, done, 2)
+
+	fast := b.Subscribe(10)
+	slow := b.Subscribe(1)
+	cancelled := b.Subscribe(10)
+	cancelled.Cancel()
+	cancelled.Cancel()
+
+	for _, line := range []string{"a", "b", "c"} {
+		output <- line
+	}
+	done <- true
+	<-b.Done()
+
+	if lines := collect(fast.Lines); !reflect.DeepEqual(lines, []string{"a", "b", "c"}) {
+		t.Errorf("Expected all lines, got %v", lines)
+	}
+	if lines := collect(slow.Lines); !reflect.DeepEqual(lines, []string{"a"}) || slow.Dropped() != 2 {
+		t.Errorf("Expected slow subscriber to drop 2 lines, got %v and %d dropped", lines, slow.Dropped())
+	}
+	if lines := collect(cancelled.Lines); len(lines) != 0 {
+		t.Errorf("Expected no lines after cancel, got %v", lines)
+	}
+
+	late := b.Subscribe(0)
+	if lines := collect(late.Lines); !reflect.DeepEqual(lines, []string{"b", "c"}) {
+		t.Errorf("Expected replay of the last 2 lines, got %v", lines)
+	}
+}
@@ -0,0 +1,48 @@
+package easyssh
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// returns the certificate to use with the private key read from keyFile (which
+// is empty for keys given as data). Certificate and CertificateData apply to
+// the primary key only, otherwise keyFile + "-cert.pub" is used if it exists.
+func (ssh_conf *MakeConfig) certificateFor(keyFile string, primary bool) ([]byte, error) {
+	if primary && len(ssh_conf.CertificateData) > 0 {
+		return ssh_conf.CertificateData, nil
+	}
+	if ssh_conf.InMemory {
+		return nil, nil
+	}
+	if primary && ssh_conf.Certificate != "" {
+		return ioutil.ReadFile(ssh_conf.Certificate)
+	}
+
+	if keyFile != "" {
+		if _, err := os.Stat(keyFile + "-cert.pub"); err == nil {
+			return ioutil.ReadFile(keyFile + "-cert.pub")
+		}
+	}
+
+	return nil, nil
+}
+
+// certSigner parses an OpenSSH certificate in authorized_keys format and
+// returns a signer presenting it together with the private key signer.
+func certSigner(data []byte, signer ssh.Signer) (ssh.Signer, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing certificate: %s", err)
+	}
+
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("Not a certificate: %s", pub.Type())
+	}
+
+	return ssh.NewCertSigner(cert, signer)
+}
@@ -0,0 +1,67 @@
+package easyssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestSigner(t *testing.T) ssh.Signer {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("Error creating signer: %s", err)
+	}
+	return signer
+}
+
+func TestCertSigner(t *testing.T) {
+	ca, userKey := newTestSigner(t), newTestSigner(t)
+
+	cert := &ssh.Certificate{
+		Key:             userKey.PublicKey(),
+		CertType:        ssh.UserCert,
+		ValidPrincipals: []string{"john"},
+		ValidBefore:     ssh.CertTimeInfinity,
+	}
+	if err := cert.SignCert(rand.Reader, ca); err != nil {
+		t.Fatalf("Error signing certificate: %s", err)
+	}
+
+	signer, err := certSigner(ssh.MarshalAuthorizedKey(cert), userKey)
+	if err != nil {
+		t.Fatalf("Error creating certificate signer: %s", err)
+	}
+	if _, ok := signer.PublicKey().(*ssh.Certificate); !ok {
+		t.Errorf("Expected signer to present the certificate, got %s", signer.PublicKey().Type())
+	}
+
+	if _, err := certSigner(ssh.MarshalAuthorizedKey(userKey.PublicKey()), userKey); err == nil {
+		t.Errorf("Expected error for plain public key used as certificate")
+	}
+
+	if _, err := certSigner(ssh.MarshalAuthorizedKey(cert), newTestSigner(t)); err == nil {
+		t.Errorf("Expected error for certificate of another key")
+	}
+}
+
+func TestParsingCertificateFileFromClientConfig(t *testing.T) {
+	cfg := `
+Host ca
+	IdentityFile /keys/id_ed25519
+	CertificateFile /keys/id_ed25519-cert.pub
+`
+	result, err := parseClientConfig(strings.NewReader(cfg), "ca")
+	if err != nil {
+		t.Fatalf("Error parsing config: %s", err)
+	}
+	if result.Certificate != "/keys/id_ed25519-cert.pub" {
+		t.Errorf("Expected certificate file to be parsed, got '%s'", result.Certificate)
+	}
+}
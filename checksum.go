@@ -0,0 +1,110 @@
+package easyssh
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+// ErrChecksumMismatch is matched by the *ChecksumError returned if a verified
+// transfer was corrupted.
+var ErrChecksumMismatch = errors.New("Checksum mismatch")
+
+// ChecksumError is returned by Upload and Download with Verify set if the
+// SHA-256 of the remote file at Path differs from the one of the local data.
+type ChecksumError struct {
+	Path   string
+	Local  string
+	Remote string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s: %s (local %s, remote %s)", ErrChecksumMismatch, e.Path, e.Local, e.Remote)
+}
+
+func (e *ChecksumError) Is(target error) bool {
+	return target == ErrChecksumMismatch
+}
+
+// RemoteSHA256 returns the hex encoded SHA-256 of the file at path on the
+// remote machine. It is computed by sha256sum if available, otherwise the file
+// is read back using SFTP.
+func (ssh_conf *MakeConfig) RemoteSHA256(path string) (string, error) {
+	stdout, stderr, err := ssh_conf.capture("sha256sum -- " + shellQuote(path))
+	if isCommandNotFound(err) {
+		ssh_conf.logf(LogDebug1, "sha256sum not available on %s, reading back %s", ssh_conf.address(), path)
+		return ssh_conf.sftpSHA256(path)
+	}
+	if err != nil {
+		return "", remoteFileError("checksum", path, stderr, err)
+	}
+
+	fields := strings.Fields(string(stdout))
+	if len(fields) == 0 || len(fields[0]) != sha256.Size*2 {
+		return "", fmt.Errorf("Unexpected output of sha256sum: %q", stdout)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// computes the SHA-256 of the remote file at path by reading it using SFTP
+func (ssh_conf *MakeConfig) sftpSHA256(path string) (string, error) {
+	client, err := ssh_conf.Connect()
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	sftpClient, err := sftp.NewClient(client.client)
+	if err != nil {
+		return "", fmt.Errorf("Error computing checksum of %s: neither sha256sum nor SFTP available", path)
+	}
+	defer sftpClient.Close()
+
+	f, err := sftpClient.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// returns the hex encoded SHA-256 of the local file
+func localSHA256(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// verifyChecksum compares local, the SHA-256 of the data transferred, to the
+// one of the remote file at path.
+func (ssh_conf *MakeConfig) verifyChecksum(path string, local []byte) error {
+	remote, err := ssh_conf.RemoteSHA256(path)
+	if err != nil {
+		return err
+	}
+
+	if l := hex.EncodeToString(local); l != remote {
+		ssh_conf.logf(LogError, "Checksum mismatch for %s:%s", ssh_conf.address(), path)
+		return &ChecksumError{Path: path, Local: l, Remote: remote}
+	}
+	return nil
+}
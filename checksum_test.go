@@ -0,0 +1,18 @@
+package easyssh
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestChecksumError(t *testing.T) {
+	var err error = &ChecksumError{Path: "/etc/motd", Local: "aa", Remote: "bb"}
+
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected error to match ErrChecksumMismatch")
+	}
+	if !strings.Contains(err.Error(), "/etc/motd") {
+		t.Errorf("Expected path in error message, got %s", err)
+	}
+}
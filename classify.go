@@ -0,0 +1,107 @@
+package easyssh
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// Severity is the importance of a line of output as determined by a Classifier.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Classifier assigns a severity to a single line of output of a remote command.
+type Classifier func(line string) Severity
+
+// ClassifierRule maps all lines matching Pattern to Severity.
+type ClassifierRule struct {
+	Pattern  *regexp.Regexp
+	Severity Severity
+}
+
+// RegexpClassifier returns a Classifier using the severity of the first rule
+// matching a line. Lines not matching any rule are classified as SeverityInfo.
+func RegexpClassifier(rules ...ClassifierRule) Classifier {
+	return func(line string) Severity {
+		for _, rule := range rules {
+			if rule.Pattern.MatchString(line) {
+				return rule.Severity
+			}
+		}
+		return SeverityInfo
+	}
+}
+
+// Line is a line of output annotated with its severity.
+type Line struct {
+	Text     string
+	Severity Severity
+}
+
+// OutputError is returned by Run if FailOnError is set and the command printed
+// a line classified as SeverityError.
+type OutputError struct {
+	Line string
+}
+
+func (e *OutputError) Error() string {
+	return fmt.Sprintf("Command reported an error: %s", e.Line)
+}
+
+// classifies line using the Classifier of MakeConfig
+func (ssh_conf *MakeConfig) classify(line string) Severity {
+	if ssh_conf.Classifier == nil {
+		return SeverityInfo
+	}
+	return ssh_conf.Classifier(line)
+}
+
+// StreamClassified works like Stream, but annotates every line of output with
+// the severity assigned by the Classifier of MakeConfig.
+func (ssh_conf *MakeConfig) StreamClassified(command string) (output chan Line, done chan bool, err error) {
+	return ssh_conf.StreamClassifiedWithInput(command, nil)
+}
+
+// StreamClassifiedWithInput works like StreamWithInput, but annotates every
+// line of output with the severity assigned by the Classifier of MakeConfig.
+func (ssh_conf *MakeConfig) StreamClassifiedWithInput(command string, stdin io.Reader) (output chan Line, done chan bool, err error) {
+	outChan, doneChan, err := ssh_conf.StreamWithInput(command, stdin)
+	if err != nil {
+		return output, done, err
+	}
+
+	output = make(chan Line)
+	done = make(chan bool)
+	ssh_conf.spawn(func() {
+		defer close(output)
+		defer close(done)
+		for {
+			select {
+			case <-doneChan:
+				done <- true
+				return
+			case line := <-outChan:
+				output <- Line{Text: line, Severity: ssh_conf.classify(line)}
+			}
+		}
+	})
+
+	return output, done, err
+}
@@ -0,0 +1,28 @@
+package easyssh
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRegexpClassifier(t *testing.T) {
+	classify := RegexpClassifier(
+		ClassifierRule{regexp.MustCompile(`(?i)^error:`), SeverityError},
+		ClassifierRule{regexp.MustCompile(`(?i)warn`), SeverityWarning},
+		ClassifierRule{regexp.MustCompile(`(?i)error`), SeverityWarning},
+	)
+
+	testCases := map[string]Severity{
+		"":                         SeverityInfo,
+		"Reading package lists...": SeverityInfo,
+		"W: some warning":          SeverityWarning,
+		"ERROR: disk full":         SeverityError,
+		"0 errors, 2 warnings":     SeverityWarning,
+	}
+
+	for input, expected := range testCases {
+		if result := classify(input); result != expected {
+			t.Errorf("Expected %s for '%s', got %s", expected, input, result)
+		}
+	}
+}
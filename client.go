@@ -0,0 +1,149 @@
+package easyssh
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Client is an established connection to a remote machine. It gives access to
+// the underlying *ssh.Client for features easyssh does not wrap (yet). A Client
+// may be used by multiple goroutines at once, each command running in a session
+// of its own.
+type Client struct {
+	config     *MakeConfig
+	client     *ssh.Client
+	authMethod AuthMethod
+}
+
+// Connect connects to the remote machine and returns the established
+// connection. It needs to be closed by the caller.
+func (ssh_conf *MakeConfig) Connect() (*Client, error) {
+	var authMethod string
+	client, err := ssh_conf.dial(&authMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	if ssh_conf.ForwardAgent {
+		if err := ssh_conf.forwardAgent(client); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	return &Client{config: ssh_conf, client: client, authMethod: AuthMethod(authMethod)}, nil
+}
+
+// AuthMethod returns the authentication method which succeeded when connecting.
+func (c *Client) AuthMethod() AuthMethod {
+	return c.authMethod
+}
+
+// SSHClient returns the underlying *ssh.Client.
+func (c *Client) SSHClient() *ssh.Client {
+	return c.client
+}
+
+// NewSession opens a new session on the connection, with the environment
+// variables of the MakeConfig already set and agent forwarding requested if
+// ForwardAgent is set.
+func (c *Client) NewSession() (*ssh.Session, error) {
+	session, err := c.client.NewSession()
+	if err != nil {
+		c.config.logf(LogError, "Error opening session on %s: %s", c.config.address(), err)
+		return nil, err
+	}
+	c.config.logf(LogDebug2, "Opened session on %s", c.config.address())
+
+	if err := c.config.setenv(session); err != nil {
+		session.Close()
+		if isDropbear(c.client.ServerVersion()) {
+			return nil, fmt.Errorf("%s (dropbear does not support this, use InlineEnv instead)", err)
+		}
+		return nil, err
+	}
+
+	if err := c.config.requestAgentForwarding(session); err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Run runs command in a new session on the connection and returns its combined
+// stdout and stderr. Unlike MakeConfig.Run, no new connection is made, so many
+// commands can be run quickly one after another. No pty is requested and a
+// *CommandError is returned if the command fails.
+func (c *Client) Run(command string) (string, error) {
+	var output bytes.Buffer
+	w := &lockedWriter{w: &output}
+	status, err := c.RunTo(command, w, w)
+	if err == nil && status != 0 {
+		err = &CommandError{Command: command, ExitStatus: status}
+	}
+	if err == nil && c.config.FailOnError {
+		for _, line := range strings.Split(output.String(), "\n") {
+			if c.config.classify(line) == SeverityError {
+				return output.String(), &OutputError{Line: line}
+			}
+		}
+	}
+	return output.String(), err
+}
+
+// RunTo works like MakeConfig.RunTo, but runs command in a new session on the
+// connection.
+func (c *Client) RunTo(command string, stdout, stderr io.Writer) (exitStatus int, err error) {
+	session, err := c.NewSession()
+	if err != nil {
+		return -1, err
+	}
+	defer session.Close()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	return exitStatusOf(c.config.run(session, command))
+}
+
+// ownedSession is a session on a connection of its own. Closing it closes the
+// connection as well.
+type ownedSession struct {
+	*ssh.Session
+	client *Client
+}
+
+func (s *ownedSession) Close() error {
+	err := s.Session.Close()
+	s.client.Close()
+	return err
+}
+
+// lockedWriter serializes writes to w, so it can be used for stdout and stderr
+// of a session at the same time.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// Labels returns the labels of the MakeConfig the client was connected with.
+func (c *Client) Labels() map[string]string {
+	return c.config.Labels
+}
+
+// Close closes the connection and all sessions opened on it.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
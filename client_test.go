@@ -0,0 +1,65 @@
+package easyssh
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestClientRun(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.Handle("false", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.WriteString(stderr, "failed\n")
+		return 1
+	})
+
+	client, err := cfg.Connect()
+	if err != nil {
+		t.Fatalf("Error connecting: %s", err)
+	}
+	defer client.Close()
+
+	if out, err := client.Run("uptime"); err != nil || out != "uptime\n" {
+		t.Errorf("Expected output of command, got %q (%v)", out, err)
+	}
+
+	out, err := client.Run("false")
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.ExitStatus != 1 {
+		t.Errorf("Expected *CommandError with exit status 1, got %v", err)
+	}
+	if out != "failed\n" {
+		t.Errorf("Expected stderr in output, got %q", out)
+	}
+}
+
+func TestClientRunTo(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.Handle("split", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.WriteString(stdout, "out")
+		io.WriteString(stderr, "err")
+		return 3
+	})
+
+	client, err := cfg.Connect()
+	if err != nil {
+		t.Fatalf("Error connecting: %s", err)
+	}
+	defer client.Close()
+
+	var stdout, stderr bytes.Buffer
+	status, err := client.RunTo("split", &stdout, &stderr)
+	if err != nil || status != 3 {
+		t.Errorf("Expected exit status 3 without error like MakeConfig.RunTo, got %d (%v)", status, err)
+	}
+	if stdout.String() != "out" || stderr.String() != "err" {
+		t.Errorf("Expected separate stdout and stderr, got %q and %q", stdout.String(), stderr.String())
+	}
+
+	if status, err := cfg.RunTo("split", nil, nil); err != nil || status != 3 {
+		t.Errorf("Expected MakeConfig.RunTo to behave the same, got %d (%v)", status, err)
+	}
+}
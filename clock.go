@@ -0,0 +1,35 @@
+package easyssh
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Clock is the source of time used for retries, timeouts, quotas and expiry
+// checks. Tests can use a fake one to run without waiting.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the Clock of the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// returns the Clock of MakeConfig, defaulting to the real one
+func (ssh_conf *MakeConfig) clock() Clock {
+	if ssh_conf == nil || ssh_conf.Clock == nil {
+		return realClock{}
+	}
+	return ssh_conf.Clock
+}
+
+// returns the random source of MakeConfig, defaulting to math/rand
+func (ssh_conf *MakeConfig) random() func() float64 {
+	if ssh_conf == nil || ssh_conf.Rand == nil {
+		return rand.Float64
+	}
+	return ssh_conf.Rand
+}
@@ -0,0 +1,72 @@
+package easyssh
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock does not wait at all, but advances its time by every duration
+// waited for.
+type fakeClock struct {
+	now    time.Time
+	waited []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waited = append(c.waited, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestRetryWithFakeClock(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	p := &RetryPolicy{MaxAttempts: 4, Backoff: time.Second, Jitter: 0.5}
+
+	random := func() float64 { return 1 }
+	err := p.do(context.Background(), clock, random, isUnreachable, func() error {
+		return ErrHostUnreachable
+	})
+	if !errors.Is(err, ErrHostUnreachable) {
+		t.Errorf("Expected last error, got %v", err)
+	}
+
+	expected := []time.Duration{1500 * time.Millisecond, 3 * time.Second, 6 * time.Second}
+	if !reflect.DeepEqual(clock.waited, expected) {
+		t.Errorf("Expected delays %v, got %v", expected, clock.waited)
+	}
+}
+
+func TestQuotaLifetimeWithFakeClock(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cfg := &MakeConfig{Clock: clock, Quota: &Quota{MaxLifetime: time.Minute}}
+
+	if err := cfg.checkQuota(false); err != nil {
+		t.Errorf("Expected first use to be allowed, got %s", err)
+	}
+	clock.now = clock.now.Add(2 * time.Minute)
+	if err := cfg.checkQuota(false); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected MaxLifetime to be exceeded, got %v", err)
+	}
+}
+
+func TestExpiryWithFakeClock(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	cfg := &MakeConfig{Clock: clock, CredentialsExpire: time.Unix(2000, 0)}
+
+	if err := cfg.checkExpiry(nil); err != nil {
+		t.Errorf("Expected credentials to be valid, got %s", err)
+	}
+	clock.now = time.Unix(2000, 0)
+	if err := cfg.checkExpiry(nil); !errors.Is(err, ErrCredentialsExpired) {
+		t.Errorf("Expected credentials to be expired, got %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package easyssh
+
+import (
+	"regexp"
+	"strings"
+)
+
+var safeWordRegex = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// Quote quotes s for use as a single word in a POSIX shell command line. Words
+// which need no quoting are returned as is.
+func Quote(s string) string {
+	if safeWordRegex.MatchString(s) {
+		return s
+	}
+	return shellQuote(s)
+}
+
+// Command returns the command line running name with args, each quoted using
+// Quote, so values with spaces or shell metacharacters are passed on unaltered
+// and cannot inject further commands.
+func Command(name string, args ...string) string {
+	words := make([]string, 0, len(args)+1)
+	words = append(words, Quote(name))
+	for _, arg := range args {
+		words = append(words, Quote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// RunArgs runs name with args on the remote machine like Run, quoting every
+// argument (see Command).
+func (ssh_conf *MakeConfig) RunArgs(name string, args ...string) (string, error) {
+	return ssh_conf.Run(Command(name, args...))
+}
@@ -0,0 +1,22 @@
+package easyssh
+
+import "testing"
+
+func TestCommand(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"ls", []string{"-la", "/var/log"}, "ls -la /var/log"},
+		{"cat", []string{"my file.txt"}, "cat 'my file.txt'"},
+		{"echo", []string{"it's"}, `echo 'it'\''s'`},
+		{"echo", []string{"$(reboot)", "; rm -rf /"}, "echo '$(reboot)' '; rm -rf /'"},
+		{"printf", []string{""}, "printf ''"},
+		{"my tool", nil, "'my tool'"},
+	} {
+		if cmd := Command(tc.name, tc.args...); cmd != tc.expected {
+			t.Errorf("Expected %s, got %s", tc.expected, cmd)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package easyssh
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// withEnv prefixes command with exports of the environment variables from
+// MakeConfig if InlineEnv is set. This is needed for servers like dropbear
+// which reject environment variables sent as session requests.
+func (ssh_conf *MakeConfig) withEnv(command string) (string, error) {
+	if !ssh_conf.InlineEnv || len(ssh_conf.Env) == 0 {
+		return command, nil
+	}
+
+	names := make([]string, 0, len(ssh_conf.Env))
+	for name := range ssh_conf.Env {
+		if !envNameRegex.MatchString(name) {
+			return "", fmt.Errorf("Invalid environment variable name '%s'", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	exports := make([]string, len(names))
+	for i, name := range names {
+		exports[i] = "export " + name + "=" + shellQuote(ssh_conf.Env[name]) + "; "
+	}
+
+	return strings.Join(exports, "") + command, nil
+}
+
+// isDropbear returns true if version is the version string of a dropbear server
+// (ex. "SSH-2.0-dropbear_2019.78").
+func isDropbear(version []byte) bool {
+	return strings.HasPrefix(strings.ToLower(string(version)), "ssh-2.0-dropbear")
+}
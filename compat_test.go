@@ -0,0 +1,65 @@
+package easyssh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithEnv(t *testing.T) {
+	cfg := &MakeConfig{Env: map[string]string{"B": "it's", "A": "1"}}
+
+	command, err := cfg.withEnv("echo $A")
+	if err != nil || command != "echo $A" {
+		t.Errorf("Expected command to be unchanged without InlineEnv, got '%s' (%v)", command, err)
+	}
+
+	cfg.InlineEnv = true
+	command, err = cfg.withEnv("echo $A")
+	if err != nil {
+		t.Fatalf("Error building command: %s", err)
+	}
+	expected := `export A='1'; export B='it'\''s'; echo $A`
+	if command != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, command)
+	}
+
+	cfg.Env = map[string]string{"A; rm -rf /": "x"}
+	if _, err := cfg.withEnv("true"); err == nil {
+		t.Errorf("Expected error for invalid variable name")
+	}
+}
+
+func TestSetenvRequests(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	cfg.Env = map[string]string{"LANG": "C", "APP_ENV": "production"}
+
+	if _, err := cfg.Run("env"); err != nil {
+		t.Fatalf("Error running command: %s", err)
+	}
+	expected := []string{"APP_ENV=production", "LANG=C"}
+	if env := srv.Env(); !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected env requests %v, got %v", expected, env)
+	}
+
+	cfg.InlineEnv = true
+	if _, err := cfg.Run("env"); err != nil {
+		t.Fatalf("Error running command: %s", err)
+	}
+	if env := srv.Env(); len(env) != len(expected) {
+		t.Errorf("Expected no env requests with InlineEnv, got %v", env[len(expected):])
+	}
+}
+
+func TestIsDropbear(t *testing.T) {
+	for version, expected := range map[string]bool{
+		"SSH-2.0-dropbear_2019.78": true,
+		"SSH-2.0-dropbear":         true,
+		"SSH-2.0-OpenSSH_8.9p1":    false,
+		"":                         false,
+	} {
+		if isDropbear([]byte(version)) != expected {
+			t.Errorf("Expected isDropbear(%q) to be %v", version, expected)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package easyssh
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrentRun(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			command := fmt.Sprintf("echo %d", i)
+			if out, err := cfg.Run(command); err != nil || out != command+"\n" {
+				t.Errorf("Expected %q, got %q (%v)", command+"\n", out, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestConcurrentClientRun(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+
+	client, err := cfg.Connect()
+	if err != nil {
+		t.Fatalf("Error connecting: %s", err)
+	}
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			command := fmt.Sprintf("echo %d", i)
+			if out, err := client.Run(command); err != nil || out != command+"\n" {
+				t.Errorf("Expected %q, got %q (%v)", command+"\n", out, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestStreamStartErrorDoesNotLeak(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	cfg.Quota = &Quota{MaxCommands: 1}
+
+	if _, err := cfg.Run("true"); err != nil {
+		t.Fatalf("Error running command: %s", err)
+	}
+	if _, err := cfg.Run("true"); err == nil {
+		t.Fatalf("Expected the quota to be exceeded")
+	}
+	if output, done, err := cfg.Stream("true"); err == nil || output != nil || done != nil {
+		t.Errorf("Expected only an error, got %v, %v and %v", output, done, err)
+	}
+	if _, _, err := cfg.StreamRaw("true"); err == nil {
+		t.Errorf("Expected an error")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := cfg.WaitIdle(ctx); err != nil {
+		t.Errorf("Expected no goroutines left running, got %s", err)
+	}
+}
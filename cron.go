@@ -0,0 +1,238 @@
+package easyssh
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var unitNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.@\-]+$`)
+
+// crontabCommand returns the crontab invocation for user. If user is empty, the
+// crontab of the user logged in is used.
+func crontabCommand(user string, args string) string {
+	if user == "" {
+		return "crontab " + args
+	}
+	return "crontab -u " + shellQuote(user) + " " + args
+}
+
+// Crontab returns the lines of the crontab of user on the remote machine. If
+// user is empty, the crontab of the user logged in is returned. A missing
+// crontab is returned as empty list. The crontab of another user is read using
+// Escalation, if configured.
+func (ssh_conf *MakeConfig) Crontab(user string) ([]string, error) {
+	var stdout, stderr []byte
+	var err error
+	if user == "" {
+		stdout, stderr, err = ssh_conf.capture(crontabCommand(user, "-l"))
+	} else {
+		stdout, stderr, err = ssh_conf.privilegedCapture(crontabCommand(user, "-l"))
+	}
+	if err != nil {
+		if strings.Contains(string(stderr), "no crontab for") {
+			return nil, nil
+		}
+		return nil, remoteError(stderr, err)
+	}
+
+	return parseCrontab(string(stdout)), nil
+}
+
+// SetCrontab replaces the crontab of user on the remote machine with lines.
+// The new crontab is passed to crontab on stdin, so its content never needs to
+// be quoted for the shell. The crontab of another user is replaced using
+// Escalation, if configured, reading it from a temporary file instead.
+func (ssh_conf *MakeConfig) SetCrontab(user string, lines []string) error {
+	for _, line := range lines {
+		if strings.ContainsAny(line, "\r\n") {
+			return fmt.Errorf("Invalid crontab line: %q", line)
+		}
+	}
+
+	content := strings.Join(lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+
+	if user != "" && ssh_conf.Escalation != nil {
+		return ssh_conf.setCrontabPrivileged(user, content)
+	}
+
+	_, stderr, err := ssh_conf.captureUntil(crontabCommand(user, "-"), strings.NewReader(content), nil)
+	if err != nil {
+		return remoteError(stderr, err)
+	}
+
+	return nil
+}
+
+// setCrontabPrivileged installs content as crontab of user using Escalation.
+// As stdin may be needed for the password, the crontab is written to a
+// temporary file first.
+func (ssh_conf *MakeConfig) setCrontabPrivileged(user, content string) error {
+	stdout, stderr, err := ssh_conf.capture("mktemp")
+	if err != nil {
+		return remoteError(stderr, err)
+	}
+	tmp := strings.TrimSpace(string(stdout))
+	if tmp == "" {
+		return errors.New("Error creating temporary file: mktemp returned no name")
+	}
+	defer ssh_conf.capture("rm -f -- " + shellQuote(tmp))
+
+	if err := ssh_conf.writeRemoteFile(tmp, content); err != nil {
+		return err
+	}
+
+	return ssh_conf.privileged(crontabCommand(user, shellQuote(tmp)))
+}
+
+// AddCronEntry adds entry (ex. "*/5 * * * * /usr/local/bin/backup") to the
+// crontab of user, unless it is already there.
+func (ssh_conf *MakeConfig) AddCronEntry(user, entry string) error {
+	lines, err := ssh_conf.Crontab(user)
+	if err != nil {
+		return err
+	}
+
+	entry = strings.TrimSpace(entry)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == entry {
+			return nil
+		}
+	}
+
+	return ssh_conf.SetCrontab(user, append(lines, entry))
+}
+
+// RemoveCronEntry removes all lines equal to entry from the crontab of user.
+func (ssh_conf *MakeConfig) RemoveCronEntry(user, entry string) error {
+	lines, err := ssh_conf.Crontab(user)
+	if err != nil {
+		return err
+	}
+
+	entry = strings.TrimSpace(entry)
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line) != entry {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) == len(lines) {
+		return nil
+	}
+
+	return ssh_conf.SetCrontab(user, kept)
+}
+
+// splits the output of crontab -l into lines
+func parseCrontab(content string) []string {
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
+
+// SystemdTimer describes a systemd timer running Command as User on the
+// schedule given by OnCalendar (ex. "daily" or "*-*-* 04:00:00").
+type SystemdTimer struct {
+	Name       string
+	User       string
+	OnCalendar string
+	Command    string
+}
+
+// units returns the content of the service and timer unit files for t.
+func (t SystemdTimer) units() (service string, timer string) {
+	service = "[Unit]\nDescription=" + t.Name + "\n\n[Service]\nType=oneshot\n"
+	if t.User != "" {
+		service += "User=" + t.User + "\n"
+	}
+	service += "ExecStart=/bin/sh -c " + systemdQuote(t.Command) + "\n"
+
+	timer = "[Unit]\nDescription=" + t.Name + "\n\n[Timer]\nOnCalendar=" + t.OnCalendar +
+		"\nPersistent=true\n\n[Install]\nWantedBy=timers.target\n"
+
+	return service, timer
+}
+
+// systemdQuote quotes s as a single argument of an Exec line of a unit file.
+func systemdQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "%", "%%", -1)
+	s = strings.Replace(s, "$", "$$", -1)
+	return `"` + s + `"`
+}
+
+func (t SystemdTimer) validate() error {
+	if !unitNameRegex.MatchString(t.Name) {
+		return fmt.Errorf("Invalid timer name: '%s'", t.Name)
+	}
+	for _, value := range []string{t.User, t.OnCalendar, t.Command} {
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("Invalid value for timer '%s': %q", t.Name, value)
+		}
+	}
+	if t.OnCalendar == "" || t.Command == "" {
+		return errors.New("Timer needs OnCalendar and Command")
+	}
+	return nil
+}
+
+// AddSystemdTimer installs and starts a systemd timer on the remote machine,
+// replacing an existing one of the same name. This needs root privileges (see
+// Escalation).
+func (ssh_conf *MakeConfig) AddSystemdTimer(t SystemdTimer) error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+
+	service, timer := t.units()
+	base := "/etc/systemd/system/" + t.Name
+
+	if err := ssh_conf.privilegedWrite(base+".service", service); err != nil {
+		return err
+	}
+	if err := ssh_conf.privilegedWrite(base+".timer", timer); err != nil {
+		return err
+	}
+
+	return ssh_conf.privileged("systemctl daemon-reload && systemctl enable --now " + shellQuote(t.Name+".timer"))
+}
+
+// RemoveSystemdTimer stops and removes a systemd timer previously installed by
+// AddSystemdTimer. Removing a timer which does not exist is no error.
+func (ssh_conf *MakeConfig) RemoveSystemdTimer(name string) error {
+	if !unitNameRegex.MatchString(name) {
+		return fmt.Errorf("Invalid timer name: '%s'", name)
+	}
+
+	base := shellQuote("/etc/systemd/system/" + name)
+	command := "systemctl disable --now " + shellQuote(name+".timer") + " 2>/dev/null; " +
+		"rm -f " + base + ".timer " + base + ".service && systemctl daemon-reload"
+
+	return ssh_conf.privileged(command)
+}
+
+// writeRemoteFile replaces the file at path on the remote machine with
+// content, which is passed on stdin.
+func (ssh_conf *MakeConfig) writeRemoteFile(path, content string) error {
+	_, stderr, err := ssh_conf.captureUntil("cat > "+shellQuote(path), strings.NewReader(content), nil)
+	if err != nil {
+		return remoteFileError("write", path, stderr, err)
+	}
+	return nil
+}
+
+// remoteError adds the stderr output of a failed remote command to err.
+func remoteError(stderr []byte, err error) error {
+	if msg := strings.TrimSpace(string(stderr)); msg != "" {
+		return fmt.Errorf("%w: %s", err, msg)
+	}
+	return err
+}
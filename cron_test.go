@@ -0,0 +1,114 @@
+package easyssh
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCrontab(t *testing.T) {
+	lines := parseCrontab("# m h dom mon dow command\n*/5 * * * * /usr/local/bin/backup\n")
+	if len(lines) != 2 || lines[1] != "*/5 * * * * /usr/local/bin/backup" {
+		t.Errorf("Unexpected crontab lines: %q", lines)
+	}
+
+	if lines := parseCrontab(""); len(lines) != 0 {
+		t.Errorf("Expected empty crontab, got %q", lines)
+	}
+}
+
+func TestCrontabCommand(t *testing.T) {
+	if result := crontabCommand("", "-l"); result != "crontab -l" {
+		t.Errorf("Unexpected command: %s", result)
+	}
+	if result := crontabCommand("www-data", "-"); result != "crontab -u 'www-data' -" {
+		t.Errorf("Unexpected command: %s", result)
+	}
+}
+
+func TestSystemdTimerUnits(t *testing.T) {
+	timer := SystemdTimer{Name: "backup", User: "backup", OnCalendar: "daily", Command: `echo "100%" > $HOME/x`}
+	if err := timer.validate(); err != nil {
+		t.Fatalf("Unexpected validation error: %s", err)
+	}
+
+	service, timerUnit := timer.units()
+	if !strings.Contains(service, "User=backup\n") {
+		t.Errorf("Expected service to run as backup, got:\n%s", service)
+	}
+	if expected := `ExecStart=/bin/sh -c "echo \"100%%\" > $$HOME/x"`; !strings.Contains(service, expected) {
+		t.Errorf("Expected service to contain %s, got:\n%s", expected, service)
+	}
+	if !strings.Contains(timerUnit, "OnCalendar=daily\n") {
+		t.Errorf("Expected timer to run daily, got:\n%s", timerUnit)
+	}
+
+	for _, invalid := range []SystemdTimer{
+		{Name: "../evil", OnCalendar: "daily", Command: "true"},
+		{Name: "ok", OnCalendar: "daily\nExecStart=/bin/evil", Command: "true"},
+		{Name: "ok", Command: "true"},
+	} {
+		if err := invalid.validate(); err == nil {
+			t.Errorf("Expected validation error for %+v", invalid)
+		}
+	}
+}
+
+func TestCrontabError(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.Handle("crontab -u 'nobody' -l", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.WriteString(stderr, "crontab: user nobody not allowed\n")
+		return 1
+	})
+
+	_, err := cfg.Crontab("nobody")
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.ExitStatus != 1 || !errors.Is(err, ErrCommandFailed) {
+		t.Errorf("Expected *CommandError with exit status 1, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("Expected stderr in error message, got %v", err)
+	}
+}
+
+func TestCrontabEscalation(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	cfg.Escalation = &Escalation{}
+
+	var installed string
+	srv.Handle("sudo -n -- sh -c 'crontab -u '\\''www'\\'' -l'", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.WriteString(stdout, "@daily /usr/local/bin/backup\n")
+		return 0
+	})
+	srv.Handle("mktemp", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.WriteString(stdout, "/tmp/tmp.cron\n")
+		return 0
+	})
+	srv.Handle("cat > '/tmp/tmp.cron'", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		data, _ := ioutil.ReadAll(stdin)
+		installed = string(data)
+		return 0
+	})
+
+	if err := cfg.AddCronEntry("www", "*/5 * * * * /usr/local/bin/sync"); err != nil {
+		t.Fatalf("Error adding cron entry: %s", err)
+	}
+	if installed != "@daily /usr/local/bin/backup\n*/5 * * * * /usr/local/bin/sync\n" {
+		t.Errorf("Unexpected crontab: %q", installed)
+	}
+	expected := []string{
+		"sudo -n -- sh -c 'crontab -u '\\''www'\\'' -l'",
+		"mktemp",
+		"cat > '/tmp/tmp.cron'",
+		"sudo -n -- sh -c 'crontab -u '\\''www'\\'' '\\''/tmp/tmp.cron'\\'''",
+		"rm -f -- '/tmp/tmp.cron'",
+	}
+	if commands := srv.Commands(); !reflect.DeepEqual(commands, expected) {
+		t.Errorf("Expected %q, got %q", expected, commands)
+	}
+}
@@ -0,0 +1,46 @@
+package easyssh
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// ContextDialer is implemented by Client. It is what database drivers and
+// other libraries usually accept to make their connections, so they can reach
+// servers only reachable from the remote machine (ex. a database behind a
+// bastion host). For the common drivers:
+//
+//	// github.com/jackc/pgx
+//	config.DialFunc = client.DialContext
+//
+//	// github.com/lib/pq
+//	connector, err := pq.NewConnector("host=db.internal user=app dbname=app")
+//	connector.Dialer(client)
+//	db := sql.OpenDB(connector)
+//
+//	// github.com/go-sql-driver/mysql
+//	mysql.RegisterDialContext("ssh", client.DialContextFunc("tcp"))
+//	db, err := sql.Open("mysql", "app:secret@ssh(db.internal:3306)/app")
+type ContextDialer interface {
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+var _ ContextDialer = (*Client)(nil)
+
+// DialTimeout works like Dial, but gives up after timeout. With it, Client
+// implements the Dialer interface of github.com/lib/pq.
+func (c *Client) DialTimeout(network, addr string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return c.DialContext(ctx, network, addr)
+}
+
+// DialContextFunc returns a function connecting to addresses using network
+// through the client, as needed by drivers which are only given the address
+// (ex. RegisterDialContext of github.com/go-sql-driver/mysql).
+func (c *Client) DialContextFunc(network string) func(ctx context.Context, addr string) (net.Conn, error) {
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		return c.DialContext(ctx, network, addr)
+	}
+}
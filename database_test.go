@@ -0,0 +1,47 @@
+package easyssh
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestClientDatabaseDialers(t *testing.T) {
+	srv, client := connectTestServer(t)
+	defer srv.Close()
+	defer client.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("R"))
+			conn.Close()
+		}
+	}()
+
+	check := func(name string, conn net.Conn, err error) {
+		if err != nil {
+			t.Errorf("%s failed: %s", name, err)
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 1)
+		if _, err := conn.Read(buf); err != nil || buf[0] != 'R' {
+			t.Errorf("Expected greeting via %s, got %q (%v)", name, buf, err)
+		}
+	}
+
+	conn, err := client.DialTimeout("tcp", l.Addr().String(), 5*time.Second)
+	check("DialTimeout", conn, err)
+	conn, err = client.DialContextFunc("tcp")(context.Background(), l.Addr().String())
+	check("DialContextFunc", conn, err)
+}
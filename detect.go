@@ -0,0 +1,123 @@
+package easyssh
+
+import (
+	"bufio"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// FileExists returns whether path exists on the remote machine, following
+// symbolic links.
+func (ssh_conf *MakeConfig) FileExists(path string) (bool, error) {
+	_, stderr, err := ssh_conf.capture("test -e " + shellQuote(path))
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) && cmdErr.ExitStatus == 1 {
+		return false, nil
+	}
+	if err != nil {
+		return false, remoteError(stderr, err)
+	}
+	return true, nil
+}
+
+// Which returns the path of the executable binary found in the PATH of the
+// remote machine (like command -v). If there is none, an *exec.Error matching
+// exec.ErrNotFound is returned.
+func (ssh_conf *MakeConfig) Which(binary string) (string, error) {
+	stdout, stderr, err := ssh_conf.capture("command -v " + shellQuote(binary))
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		return "", &exec.Error{Name: binary, Err: exec.ErrNotFound}
+	}
+	if err != nil {
+		return "", remoteError(stderr, err)
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+// OSInfo describes the operating system of a remote machine. Kernel, Release
+// and Arch are reported by uname (ex. "Linux", "6.1.0-18-amd64" and "x86_64"),
+// the other fields are taken from /etc/os-release and empty if it is missing
+// (ex. on macOS or BSDs).
+type OSInfo struct {
+	Kernel  string
+	Release string
+	Arch    string
+	// ID is the lower case name of the distribution (ex. "debian" or
+	// "alpine"), IDLike the ones it is derived from (ex. "rhel fedora").
+	ID        string
+	IDLike    []string
+	VersionID string
+	// Name is the human readable name (ex. "Debian GNU/Linux 12 (bookworm)").
+	Name string
+}
+
+// osCommand prints the output of uname followed by the os-release file.
+const osCommand = "uname -s; uname -r; uname -m; " +
+	"cat /etc/os-release 2>/dev/null || cat /usr/lib/os-release 2>/dev/null || true"
+
+// DetectOS returns the operating system of the remote machine.
+func (ssh_conf *MakeConfig) DetectOS() (*OSInfo, error) {
+	stdout, stderr, err := ssh_conf.capture(osCommand)
+	if err != nil {
+		return nil, remoteError(stderr, err)
+	}
+	return parseOS(string(stdout))
+}
+
+// parseOS parses the output of osCommand.
+func parseOS(output string) (*OSInfo, error) {
+	lines := strings.SplitN(output, "\n", 4)
+	if len(lines) < 3 {
+		return nil, errors.New("Error detecting OS: uname returned too little output")
+	}
+	info := &OSInfo{
+		Kernel:  strings.TrimSpace(lines[0]),
+		Release: strings.TrimSpace(lines[1]),
+		Arch:    strings.TrimSpace(lines[2]),
+	}
+	if len(lines) < 4 {
+		return info, nil
+	}
+
+	release := parseOSRelease(lines[3])
+	info.ID = release["ID"]
+	if idLike := release["ID_LIKE"]; idLike != "" {
+		info.IDLike = strings.Fields(idLike)
+	}
+	info.VersionID = release["VERSION_ID"]
+	info.Name = release["PRETTY_NAME"]
+	if info.Name == "" {
+		info.Name = release["NAME"]
+	}
+	return info, nil
+}
+
+// parseOSRelease returns the variables assigned in the os-release file data.
+func parseOSRelease(data string) map[string]string {
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		i := strings.IndexByte(line, '=')
+		if line == "" || line[0] == '#' || i < 0 {
+			continue
+		}
+
+		value := line[i+1:]
+		switch {
+		case strings.HasPrefix(value, `"`):
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			} else {
+				value = strings.Trim(value, `"`)
+			}
+		case strings.HasPrefix(value, "'"):
+			value = strings.Trim(value, "'")
+		}
+		vars[line[:i]] = value
+	}
+	return vars
+}
@@ -0,0 +1,80 @@
+package easyssh
+
+import (
+	"errors"
+	"io"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
+)
+
+func TestFileExists(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.Handle("test -e '/etc/hostname'", testserver.Reply("", 0))
+	srv.Handle("test -e '/missing'", testserver.Reply("", 1))
+	srv.Handle("test -e '/broken'", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.WriteString(stderr, "test: I/O error\n")
+		return 2
+	})
+
+	for path, expected := range map[string]bool{"/etc/hostname": true, "/missing": false} {
+		if exists, err := cfg.FileExists(path); err != nil || exists != expected {
+			t.Errorf("Expected %v for %s, got %v (%v)", expected, path, exists, err)
+		}
+	}
+	if _, err := cfg.FileExists("/broken"); err == nil {
+		t.Errorf("Expected an error for exit status 2")
+	}
+}
+
+func TestWhich(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.Handle("command -v 'git'", testserver.Reply("/usr/bin/git\n", 0))
+	srv.Handle("command -v 'hg'", testserver.Reply("", 1))
+
+	if path, err := cfg.Which("git"); err != nil || path != "/usr/bin/git" {
+		t.Errorf("Expected /usr/bin/git, got %q (%v)", path, err)
+	}
+	if _, err := cfg.Which("hg"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Expected exec.ErrNotFound, got %v", err)
+	}
+}
+
+func TestDetectOS(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.HandleDefault(testserver.Reply(`Linux
+5.14.0-362.el9.x86_64
+x86_64
+NAME="Rocky Linux"
+VERSION_ID="9.3"
+ID="rocky"
+ID_LIKE="rhel centos fedora"
+# comment
+PRETTY_NAME="Rocky Linux 9.3 (Blue Onyx)"
+`, 0))
+
+	info, err := cfg.DetectOS()
+	if err != nil {
+		t.Fatalf("Error detecting OS: %s", err)
+	}
+	expected := &OSInfo{Kernel: "Linux", Release: "5.14.0-362.el9.x86_64", Arch: "x86_64", ID: "rocky",
+		IDLike: []string{"rhel", "centos", "fedora"}, VersionID: "9.3", Name: "Rocky Linux 9.3 (Blue Onyx)"}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, info)
+	}
+
+	srv.HandleDefault(testserver.Reply("Darwin\n23.4.0\narm64\n", 0))
+	info, err = cfg.DetectOS()
+	if err != nil {
+		t.Fatalf("Error detecting OS: %s", err)
+	}
+	expected = &OSInfo{Kernel: "Darwin", Release: "23.4.0", Arch: "arm64"}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, info)
+	}
+}
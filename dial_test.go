@@ -0,0 +1,80 @@
+package easyssh
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestDialDirect(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	cfg := &MakeConfig{Server: "example.com", Port: "2222", Conn: local}
+	if conn, err := cfg.dialDirect(cfg.address()); err != nil || conn != local {
+		t.Errorf("Expected Conn to be used, got %v (%v)", conn, err)
+	}
+
+	var dialed string
+	cfg = &MakeConfig{Server: "example.com", Port: "2222", Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = network + " " + addr
+		return local, nil
+	}}
+	if conn, err := cfg.dialDirect(cfg.address()); err != nil || conn != local {
+		t.Errorf("Expected Dialer to be used, got %v (%v)", conn, err)
+	}
+	if dialed != "tcp example.com:2222" {
+		t.Errorf("Expected Dialer to be called with tcp example.com:2222, got '%s'", dialed)
+	}
+}
+
+func TestDialFallbacks(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	var dialed []string
+	cfg := &MakeConfig{Server: "bastion1", Port: "22", Fallbacks: []string{"bastion2", "[2001:db8::1]", "bastion3:2222"},
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			if addr != "bastion3:2222" {
+				return nil, errors.New("connection refused")
+			}
+			return local, nil
+		}}
+
+	conn, addr, release, err := cfg.dialConn()
+	if err != nil || conn != local || addr != "bastion3:2222" {
+		t.Fatalf("Expected last fallback to be connected, got %s (%v)", addr, err)
+	}
+	release()
+
+	expected := []string{"bastion1:22", "bastion2:22", "[2001:db8::1]:22", "bastion3:2222"}
+	if !reflect.DeepEqual(dialed, expected) {
+		t.Errorf("Expected addresses %v to be tried, got %v", expected, dialed)
+	}
+
+	cfg.Fallbacks = []string{"bastion2"}
+	if _, _, _, err := cfg.dialConn(); !errors.Is(err, ErrHostUnreachable) {
+		t.Errorf("Expected ErrHostUnreachable when all addresses fail, got %v", err)
+	}
+}
+
+func TestAddress(t *testing.T) {
+	testCases := []struct {
+		cfg      MakeConfig
+		expected string
+	}{
+		{MakeConfig{Server: "example.com", Port: "22"}, "example.com:22"},
+		{MakeConfig{Server: "example.com"}, "example.com:22"},
+		{MakeConfig{Server: "2001:db8::1", Port: "2222"}, "[2001:db8::1]:2222"},
+		{MakeConfig{Server: "[::1]", Port: "22"}, "[::1]:22"},
+		{MakeConfig{Server: "10.0.0.1", Port: "2222"}, "10.0.0.1:2222"},
+	}
+	for _, tc := range testCases {
+		if addr := tc.cfg.address(); addr != tc.expected {
+			t.Errorf("Expected '%s' for %q, got '%s'", tc.expected, tc.cfg.Server, addr)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package easyssh
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// DefaultDockerSocket is the socket of the Docker daemon on the remote machine
+// used if no other one is given.
+const DefaultDockerSocket = "/var/run/docker.sock"
+
+// DockerDialer returns a function connecting to the socket of the Docker
+// daemon on the remote machine, like "docker -H ssh://host" does. It ignores
+// the address it is given, so it can be passed to the official client:
+//
+//	cli, err := dockerclient.NewClientWithOpts(
+//		dockerclient.WithHost("http://docker"),
+//		dockerclient.WithDialContext(client.DockerDialer("")),
+//		dockerclient.WithAPIVersionNegotiation())
+//
+// If socket is empty, DefaultDockerSocket is used. The user logged in needs to
+// be allowed to access it (ex. by being a member of the docker group).
+func (c *Client) DockerDialer(socket string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if socket == "" {
+		socket = DefaultDockerSocket
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return c.DialContext(ctx, "unix", socket)
+	}
+}
+
+// DockerTransport returns an *http.Transport sending all requests to the
+// Docker Engine API on the remote machine (see DockerDialer), regardless of
+// the host of their URL (ex. "http://docker/v1.43/containers/json").
+func (c *Client) DockerTransport(socket string) *http.Transport {
+	transport := c.HTTPTransport()
+	transport.DialContext = c.DockerDialer(socket)
+	return transport
+}
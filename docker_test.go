@@ -0,0 +1,43 @@
+package easyssh
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestClientDockerTransport(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix sockets not available")
+	}
+
+	srv, client := connectTestServer(t)
+	defer srv.Close()
+	defer client.Close()
+
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go http.Serve(l, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Version":"24.0.7"}`))
+	}))
+
+	transport := client.DockerTransport(socket)
+	defer transport.CloseIdleConnections()
+
+	resp, err := (&http.Client{Transport: transport}).Get("http://docker/version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if body, _ := ioutil.ReadAll(resp.Body); string(body) != `{"Version":"24.0.7"}` {
+		t.Errorf("Expected response of the Docker API, got %q", body)
+	}
+}
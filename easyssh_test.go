@@ -4,6 +4,14 @@ import (
 	"testing"
 	"strings"
 	"os/user"
+	"reflect"
+	"context"
+	"errors"
+	"net"
+	"io/ioutil"
+	"path/filepath"
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
 )
 
 var sshConfig = &MakeConfig{
@@ -46,7 +54,7 @@ User user
 		Port: "22",
 	}
 
-	if *result != expected {
+	if !reflect.DeepEqual(*result, expected) {
 		t.Errorf("Expected %v, got %v", expected, *result)
 	}
 }
@@ -60,8 +68,12 @@ func TestParsingConnectionString(t *testing.T) {
 	}
 
 	testCases := map[string]MakeConfig {
-		"blub@bla": {User: "blub", Server: "bla"},
-		"blubber": {User: username, Server: "blubber"},
+		"blub@bla": {User: "blub", Server: "bla", Port: "22"},
+		"blubber": {User: username, Server: "blubber", Port: "22"},
+		"blub@bla:2222": {User: "blub", Server: "bla", Port: "2222"},
+		"[2001:db8::1]:2222": {User: username, Server: "2001:db8::1", Port: "2222"},
+		"ssh://blub@bla:2222": {User: "blub", Server: "bla", Port: "2222"},
+		"ssh://bla": {User: username, Server: "bla", Port: "22"},
 	}
 
 	for input, expected := range testCases{
@@ -78,6 +90,22 @@ func TestParsingConnectionString(t *testing.T) {
 		if c.Server != expected.Server {
 			t.Errorf("Expected hostname '%s' for '%s', got '%s'", expected.Server, input, c.Server)
 		}
+
+		if c.Port != expected.Port {
+			t.Errorf("Expected port '%s' for '%s', got '%s'", expected.Port, input, c.Port)
+		}
+	}
+}
+
+func TestInDir(t *testing.T) {
+	cfg := &MakeConfig{}
+	if result := cfg.inDir("ls"); result != "ls" {
+		t.Errorf("Expected command to be unchanged without Dir, got %s", result)
+	}
+
+	cfg.Dir = "/srv/my app"
+	if expected, result := "cd -- '/srv/my app' || exit; ls; pwd", cfg.inDir("ls; pwd"); result != expected {
+		t.Errorf("Expected %s, got %s", expected, result)
 	}
 }
 
@@ -145,3 +173,114 @@ func TestRun(t *testing.T) {
 		}
 	}
 }*/
+
+func TestScannerMaxLineSize(t *testing.T) {
+	long := strings.Repeat("x", 100*1024)
+
+	var cfg MakeConfig
+	scanner := cfg.scanner(strings.NewReader(long + "\n"))
+	if scanner.Scan() || scanner.Err() == nil {
+		t.Errorf("Expected default scanner to fail on a 100KB line")
+	}
+
+	cfg.MaxLineSize = 1024 * 1024
+	scanner = cfg.scanner(strings.NewReader(long + "\nshort\n"))
+	if !scanner.Scan() || scanner.Text() != long {
+		t.Errorf("Expected 100KB line, got error %v", scanner.Err())
+	}
+	if !scanner.Scan() || scanner.Text() != "short" {
+		t.Errorf("Expected second line, got %q", scanner.Text())
+	}
+}
+
+func TestStreamWithStatusConnectError(t *testing.T) {
+	cfg := &MakeConfig{Server: "example.com", Port: "22", User: "test", Password: "secret",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, errors.New("network down")
+		}}
+
+	output, result, err := cfg.StreamWithStatus("true")
+	if err == nil || output != nil || result != nil {
+		t.Errorf("Expected connect error and no channels, got %v", err)
+	}
+}
+
+func TestRunAndTransferWithTestServer(t *testing.T) {
+	srv, err := testserver.New("test", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	srv.Handle("echo test", testserver.Reply("test\n", 0))
+
+	cfg := &MakeConfig{User: "test", Password: "secret", Server: srv.Host(), Port: srv.Port(),
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey())}
+
+	if out, err := cfg.Run("echo test"); err != nil || out != "test\n" {
+		t.Errorf("Expected output of command, got %q (%v)", out, err)
+	}
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, "upload.txt")
+	ioutil.WriteFile(local, []byte("hello\n"), 0644)
+	if err := cfg.Upload(local, "/upload.txt"); err != nil {
+		t.Fatalf("Upload failed: %s", err)
+	}
+	if content, err := srv.ReadFile("/upload.txt"); err != nil || string(content) != "hello\n" {
+		t.Errorf("Expected uploaded file, got %q (%v)", content, err)
+	}
+
+	downloaded := filepath.Join(dir, "download.txt")
+	if err := cfg.Download("/upload.txt", downloaded); err != nil {
+		t.Fatalf("Download failed: %s", err)
+	}
+	if content, _ := ioutil.ReadFile(downloaded); string(content) != "hello\n" {
+		t.Errorf("Expected downloaded file, got %q", content)
+	}
+}
+
+func TestBannerCallback(t *testing.T) {
+	srv, err := testserver.New("test", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	srv.SetBanner("Authorized access only\n")
+
+	var banner string
+	cfg := &MakeConfig{User: "test", Password: "secret", Server: srv.Host(), Port: srv.Port(),
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey()),
+		BannerCallback: func(message string) error {
+			banner = message
+			return nil
+		}}
+
+	client, err := cfg.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Close()
+
+	if banner != "Authorized access only\n" {
+		t.Errorf("Expected banner to be passed on, got %q", banner)
+	}
+}
+
+func TestClientVersion(t *testing.T) {
+	cfg := &MakeConfig{User: "test", Server: "example.com", Port: "22", InMemory: true}
+	for version, expected := range map[string]string{
+		"":                      "",
+		"deploybot_1.4":         "SSH-2.0-deploybot_1.4",
+		"SSH-2.0-deploybot_1.4": "SSH-2.0-deploybot_1.4",
+	} {
+		cfg.ClientVersion = version
+		config, release, err := cfg.clientConfig(nil)
+		release()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.ClientVersion != expected {
+			t.Errorf("Expected '%s' for '%s', got '%s'", expected, version, config.ClientVersion)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package easyssh
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"regexp"
+
+	"github.com/pkg/sftp"
+)
+
+// AppendFile appends data to the file name on the remote machine, which is
+// created if it does not exist.
+func (ssh_conf *MakeConfig) AppendFile(name string, data []byte) error {
+	return ssh_conf.editFile(name, func(content []byte) []byte {
+		return append(content, data...)
+	})
+}
+
+// EnsureLine makes sure that the file name on the remote machine contains
+// line (ex. "PermitRootLogin no" in /etc/ssh/sshd_config). If match is not
+// nil, the first line matching it (ex. `^#?PermitRootLogin\s`) is replaced by
+// line. Otherwise, or if no line matches, line is appended. The file is
+// created if it does not exist and left untouched if it already contains line.
+func (ssh_conf *MakeConfig) EnsureLine(name, line string, match *regexp.Regexp) error {
+	return ssh_conf.editFile(name, func(content []byte) []byte {
+		return ensureLine(content, line, match)
+	})
+}
+
+// ensureLine returns content with line added or replacing the first line
+// matching match.
+func ensureLine(content []byte, line string, match *regexp.Regexp) []byte {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	for _, l := range lines {
+		if string(bytes.TrimRight(l, "\r\n")) == line {
+			return content
+		}
+	}
+
+	if match != nil {
+		for i, l := range lines {
+			if match.Match(bytes.TrimRight(l, "\r\n")) {
+				lines[i] = []byte(line + "\n")
+				return bytes.Join(lines, nil)
+			}
+		}
+	}
+
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		content = append(content, '\n')
+	}
+	return append(content, line+"\n"...)
+}
+
+// editFile replaces the content of the file name on the remote machine with
+// the one returned by edit. The new content is written to a temporary file
+// with the mode and owner of the existing file first, which is then renamed
+// to name, so readers never see a partially written file. Nothing is written
+// if the content does not change.
+func (ssh_conf *MakeConfig) editFile(name string, edit func([]byte) []byte) error {
+	return ssh_conf.withSFTP(func(c *sftp.Client) error {
+		var content []byte
+		mode := os.FileMode(0644)
+		var owner *sftp.FileStat
+
+		f, err := c.Open(name)
+		if err == nil {
+			defer f.Close()
+			info, err := f.Stat()
+			if err != nil {
+				return fileError("read", name, err)
+			}
+			mode = info.Mode().Perm()
+			owner, _ = info.Sys().(*sftp.FileStat)
+			if content, err = ioutil.ReadAll(f); err != nil {
+				return fileError("read", name, err)
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fileError("read", name, err)
+		}
+
+		edited := edit(append([]byte(nil), content...))
+		if f != nil && bytes.Equal(edited, content) {
+			return nil
+		}
+		ssh_conf.logf(LogDebug1, "Updating %s:%s (%d bytes)", ssh_conf.address(), name, len(edited))
+
+		tmp := ssh_conf.tempName(name)
+		if err := writeTemp(c, tmp, edited, mode, owner); err != nil {
+			c.Remove(tmp)
+			return fileError("write", name, err)
+		}
+		if err := c.PosixRename(tmp, name); err != nil {
+			c.Remove(tmp)
+			return fileError("rename", name, err)
+		}
+		return nil
+	})
+}
+
+// writeTemp writes content to the new file tmp with mode and, if not nil, the
+// owner of the file it replaces.
+func writeTemp(c *sftp.Client, tmp string, content []byte, mode os.FileMode, owner *sftp.FileStat) error {
+	w, err := c.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(content); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	if err := c.Chmod(tmp, mode); err != nil {
+		return err
+	}
+	if owner != nil {
+		info, err := c.Stat(tmp)
+		if err != nil {
+			return err
+		}
+		if stat, ok := info.Sys().(*sftp.FileStat); !ok || stat.UID != owner.UID || stat.GID != owner.GID {
+			return c.Chown(tmp, int(owner.UID), int(owner.GID))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package easyssh
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestEnsureLine(t *testing.T) {
+	match := regexp.MustCompile(`^#?PermitRootLogin\s`)
+	for _, c := range []struct {
+		content, line string
+		match         *regexp.Regexp
+		expected      string
+	}{
+		{"", "PermitRootLogin no", nil, "PermitRootLogin no\n"},
+		{"Port 22", "PermitRootLogin no", nil, "Port 22\nPermitRootLogin no\n"},
+		{"Port 22\nPermitRootLogin no\n", "PermitRootLogin no", match, "Port 22\nPermitRootLogin no\n"},
+		{"#PermitRootLogin yes\nPort 22\n", "PermitRootLogin no", match, "PermitRootLogin no\nPort 22\n"},
+		{"Port 22\n#PermitRootLogin yes", "PermitRootLogin no", match, "Port 22\nPermitRootLogin no\n"},
+		{"Port 22\n", "PermitRootLogin no", match, "Port 22\nPermitRootLogin no\n"},
+	} {
+		if result := string(ensureLine([]byte(c.content), c.line, c.match)); result != c.expected {
+			t.Errorf("Expected %q for %q, got %q", c.expected, c.content, result)
+		}
+	}
+}
+
+func TestEditRemoteFiles(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	if err := srv.Mkdir("/etc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.WriteFile("/etc/hosts", []byte("127.0.0.1 localhost\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.AppendFile("/etc/hosts", []byte("10.0.0.2 db\n")); err != nil {
+		t.Fatalf("Error appending to file: %s", err)
+	}
+	if err := cfg.EnsureLine("/etc/hosts", "10.0.0.3 cache", regexp.MustCompile(`\scache$`)); err != nil {
+		t.Fatalf("Error ensuring line: %s", err)
+	}
+	if err := cfg.EnsureLine("/etc/hosts", "10.0.0.4 cache", regexp.MustCompile(`\scache$`)); err != nil {
+		t.Fatalf("Error ensuring line: %s", err)
+	}
+	if err := cfg.AppendFile("/etc/motd", []byte("Welcome\n")); err != nil {
+		t.Fatalf("Error appending to a new file: %s", err)
+	}
+
+	for name, expected := range map[string]string{
+		"/etc/hosts": "127.0.0.1 localhost\n10.0.0.2 db\n10.0.0.4 cache\n",
+		"/etc/motd":  "Welcome\n",
+	} {
+		content, err := srv.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Error reading %s: %s", name, err)
+		}
+		if string(content) != expected {
+			t.Errorf("Expected %q in %s, got %q", expected, name, content)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package easyssh
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Errors which can be checked for using errors.Is. Network errors reported as
+// ErrHostUnreachable are usually worth retrying, while ErrAuthFailed and
+// ErrHostKeyMismatch need to be fixed by the user.
+var (
+	ErrAuthFailed      = errors.New("Authentication failed")
+	ErrHostUnreachable = errors.New("Host unreachable")
+	ErrHostKeyMismatch = errors.New("Host key mismatch")
+	ErrCommandFailed   = errors.New("Command failed")
+)
+
+// ConnectError is returned if connecting to a remote machine failed. Kind is
+// one of ErrAuthFailed, ErrHostUnreachable or ErrHostKeyMismatch and Err the
+// error reported by the ssh library.
+type ConnectError struct {
+	Kind error
+	Host string
+	Err  error
+}
+
+func (e *ConnectError) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", e.Kind, e.Host, e.Err)
+}
+
+func (e *ConnectError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *ConnectError) Unwrap() error {
+	return e.Err
+}
+
+// CommandError is returned if a remote command exited with a non-zero exit
+// status. It matches ErrCommandFailed and wraps the *ssh.ExitError.
+type CommandError struct {
+	Command    string
+	ExitStatus int
+	Err        error
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("Command '%s' failed with exit status %d", e.Command, e.ExitStatus)
+}
+
+func (e *CommandError) Is(target error) bool {
+	return target == ErrCommandFailed
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// commandError turns the *ssh.ExitError returned by ssh.Session.Run or
+// ssh.Session.Wait into a *CommandError and returns all other errors as is.
+func commandError(command string, err error) error {
+	if exitErr, ok := err.(*ssh.ExitError); ok {
+		return &CommandError{Command: command, ExitStatus: exitErr.ExitStatus(), Err: exitErr}
+	}
+	return err
+}
+
+// handshakeError classifies an error returned by the SSH handshake with host.
+func handshakeError(host string, err error) error {
+	var keyErr *knownhosts.KeyError
+	var revokedErr *knownhosts.RevokedError
+
+	switch {
+	case errors.As(err, &keyErr) && len(keyErr.Want) > 0, errors.As(err, &revokedErr):
+		return &ConnectError{Kind: ErrHostKeyMismatch, Host: host, Err: err}
+	case strings.Contains(err.Error(), "unable to authenticate"):
+		return &ConnectError{Kind: ErrAuthFailed, Host: host, Err: err}
+	}
+
+	return err
+}
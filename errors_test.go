@@ -0,0 +1,50 @@
+package easyssh
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestHandshakeError(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected error
+	}{
+		{fmt.Errorf("ssh: handshake failed: %w", &knownhosts.KeyError{Want: []knownhosts.KnownKey{{Filename: "known_hosts"}}}), ErrHostKeyMismatch},
+		{fmt.Errorf("ssh: handshake failed: %w", &knownhosts.RevokedError{}), ErrHostKeyMismatch},
+		{errors.New("ssh: handshake failed: ssh: unable to authenticate, attempted methods [none publickey], no supported methods remain"), ErrAuthFailed},
+	}
+
+	for _, testCase := range testCases {
+		err := handshakeError("example.com:22", testCase.err)
+		if !errors.Is(err, testCase.expected) {
+			t.Errorf("Expected '%s' to be classified as '%s', got '%s'", testCase.err, testCase.expected, err)
+		}
+		if !errors.Is(err, testCase.err) {
+			t.Errorf("Expected '%s' to wrap the original error", err)
+		}
+	}
+
+	// unknown hosts are no mismatch
+	unknown := fmt.Errorf("ssh: handshake failed: %w", &knownhosts.KeyError{})
+	if err := handshakeError("example.com:22", unknown); errors.Is(err, ErrHostKeyMismatch) {
+		t.Errorf("Expected unknown host not to be reported as mismatch")
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	err := error(&CommandError{Command: "false", ExitStatus: 1})
+
+	if !errors.Is(err, ErrCommandFailed) {
+		t.Errorf("Expected CommandError to match ErrCommandFailed")
+	}
+	if errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected CommandError not to match ErrAuthFailed")
+	}
+	if status, err := exitStatusOf(err); status != -1 || err == nil {
+		t.Errorf("Expected CommandError without *ssh.ExitError not to carry an exit status")
+	}
+}
@@ -0,0 +1,299 @@
+package easyssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// EscalationMethod is the tool used to run commands with other privileges.
+type EscalationMethod string
+
+const (
+	EscalationSudo EscalationMethod = "sudo"
+	EscalationDoas EscalationMethod = "doas"
+	EscalationSu   EscalationMethod = "su"
+)
+
+// defaultPromptRegexp matches the password prompts of doas and su.
+var defaultPromptRegexp = regexp.MustCompile(`(?i)password.*:\s*$`)
+
+// Escalation describes how commands are run with other privileges by RunSudo,
+// Become, WriteFileSudo and the helpers needing root privileges.
+type Escalation struct {
+	// Method is the tool used. The default is EscalationSudo.
+	Method EscalationMethod
+	// User is the user to become. The default is root.
+	User string
+	// Password is given to the tool if it asks for one. If empty,
+	// PasswordFunc is called to get it. Without a password, the tool is run
+	// non-interactively and fails if it needs one.
+	Password     string
+	PasswordFunc func() (string, error)
+	// Flags are additional flags passed to the tool (ex. "-H" or "-E" for sudo).
+	Flags []string
+	// PromptRegexp matches the password prompt of doas and su, which read the
+	// password from a terminal. The default matches "Password:" and
+	// "doas (john@example.com) password:". sudo is given the password on stdin
+	// without a prompt.
+	PromptRegexp *regexp.Regexp
+}
+
+// returns the Escalation of MakeConfig, defaulting to sudo
+func (ssh_conf *MakeConfig) escalation() Escalation {
+	if ssh_conf.Escalation == nil {
+		return Escalation{}
+	}
+	return *ssh_conf.Escalation
+}
+
+func (e Escalation) method() EscalationMethod {
+	if e.Method == "" {
+		return EscalationSudo
+	}
+	return e.Method
+}
+
+// password returns the password for the tool, which is empty if there is none.
+func (e Escalation) password() (string, error) {
+	if e.Password != "" || e.PasswordFunc == nil {
+		return e.Password, nil
+	}
+	return e.PasswordFunc()
+}
+
+// command wraps command so that it is run by a shell using the escalation
+// tool. If withPassword is not set, the tool is told not to ask for one.
+func (e Escalation) command(command string, withPassword bool) (string, error) {
+	var args []string
+
+	switch e.method() {
+	case EscalationSudo:
+		args = []string{"sudo"}
+		if withPassword {
+			args = append(args, "-S", "-k", "-p", "''")
+		} else {
+			args = append(args, "-n")
+		}
+		if e.User != "" {
+			args = append(args, "-u", shellQuote(e.User))
+		}
+	case EscalationDoas:
+		args = []string{"doas"}
+		if !withPassword {
+			args = append(args, "-n")
+		}
+		if e.User != "" {
+			args = append(args, "-u", shellQuote(e.User))
+		}
+	case EscalationSu:
+		user := e.User
+		if user == "" {
+			user = "root"
+		}
+		args = []string{"su"}
+		for _, flag := range e.Flags {
+			args = append(args, shellQuote(flag))
+		}
+		return strings.Join(append(args, shellQuote(user), "-c", shellQuote(command)), " "), nil
+	default:
+		return "", fmt.Errorf("Unknown escalation method '%s'", e.Method)
+	}
+
+	for _, flag := range e.Flags {
+		args = append(args, shellQuote(flag))
+	}
+	return strings.Join(append(args, "--", "sh", "-c", shellQuote(command)), " "), nil
+}
+
+// isAuthFailure checks whether the output of the escalation tool indicates that
+// the given password was not accepted.
+func isAuthFailure(output []byte) bool {
+	msg := string(output)
+	return isSudoAuthFailure(output) ||
+		strings.Contains(msg, "Authentication failed") ||
+		strings.Contains(msg, "Authentication failure")
+}
+
+// Become runs command on the remote machine as described by the Escalation of
+// MakeConfig (ex. as root using sudo) and returns its stdout. If the command
+// fails, the returned error is a *CommandError holding its exit status. For doas
+// and su, which need a terminal to read the password from, stdout and stderr
+// are combined.
+func (ssh_conf *MakeConfig) Become(command string) (string, error) {
+	stdout, _, err := ssh_conf.become(ssh_conf.escalation(), command)
+	return string(stdout), err
+}
+
+// become runs command as described by e
+func (ssh_conf *MakeConfig) become(e Escalation, command string) (stdout []byte, stderr []byte, err error) {
+	password, err := e.password()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error getting password for %s: %s", e.method(), err)
+	}
+
+	wrapped, err := e.command(command, password != "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if password == "" {
+		return ssh_conf.capture(wrapped)
+	}
+
+	if e.method() == EscalationSudo {
+		stdout, stderr, err = ssh_conf.captureUntil(wrapped, strings.NewReader(password+"\n"), nil)
+	} else {
+		stdout, err = ssh_conf.captureWithPassword(wrapped, password, e.PromptRegexp)
+		stderr = stdout
+	}
+
+	if err != nil && isAuthFailure(stderr) {
+		return stdout, stderr, fmt.Errorf("%s authentication failed: incorrect password", escalationName(e.method()))
+	}
+
+	return stdout, stderr, err
+}
+
+func escalationName(method EscalationMethod) string {
+	name := string(method)
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// promptWriter collects output and answers the first password prompt matching
+// prompt. Everything up to and including the prompt is dropped from the output.
+type promptWriter struct {
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	start    int
+	prompt   *regexp.Regexp
+	answer   func()
+	answered bool
+}
+
+func (w *promptWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	if !w.answered && w.prompt.Match(w.buf.Bytes()) {
+		w.answered = true
+		w.start = w.buf.Len()
+		w.answer()
+	}
+	return len(p), nil
+}
+
+// output returns the output following the prompt, with the line endings of the
+// terminal converted
+func (w *promptWriter) output() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	out := bytes.Replace(w.buf.Bytes()[w.start:], []byte("\r\n"), []byte("\n"), -1)
+	if w.answered {
+		out = bytes.TrimPrefix(out, []byte("\n"))
+	}
+	return out
+}
+
+// captureWithPassword runs command in a terminal and types password when the
+// prompt appears. It returns the combined output of the command.
+func (ssh_conf *MakeConfig) captureWithPassword(command, password string, prompt *regexp.Regexp) ([]byte, error) {
+	if prompt == nil {
+		prompt = defaultPromptRegexp
+	}
+
+	session, err := ssh_conf.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	err = ssh_conf.request(session.Session, func() error {
+		return session.RequestPty("dumb", 24, 80, ssh.TerminalModes{ssh.ECHO: 0})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error requesting pty: %s", err)
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &promptWriter{prompt: prompt, answer: func() {
+		ssh_conf.spawn(func() {
+			io.WriteString(stdin, password+"\n")
+		})
+	}}
+	session.Stdout = w
+	session.Stderr = w
+
+	err = ssh_conf.run(session.Session, command)
+	return w.output(), commandError(command, err)
+}
+
+// WriteFileSudo replaces the file at path on the remote machine with content,
+// using the Escalation of MakeConfig (ex. to write files owned by root). The
+// content is first written to a temporary file of the user logged in, so it
+// never mixes with the password. The mode and owner of an existing file are
+// kept.
+func (ssh_conf *MakeConfig) WriteFileSudo(path string, content []byte) error {
+	stdout, stderr, err := ssh_conf.capture("mktemp")
+	if err != nil {
+		return remoteError(stderr, err)
+	}
+	tmp := strings.TrimSpace(string(stdout))
+	if tmp == "" {
+		return errors.New("Error creating temporary file: mktemp returned no name")
+	}
+	defer ssh_conf.capture("rm -f -- " + shellQuote(tmp))
+
+	if err := ssh_conf.writeRemoteFile(tmp, string(content)); err != nil {
+		return err
+	}
+
+	_, stderr, err = ssh_conf.become(ssh_conf.escalation(), "cat -- "+shellQuote(tmp)+" > "+shellQuote(path))
+	var cmdErr *CommandError
+	if err != nil && !errors.As(err, &cmdErr) {
+		return err
+	} else if err != nil {
+		return remoteFileError("write", path, stderr, err)
+	}
+
+	return nil
+}
+
+// privilegedWrite writes content to the file at path using the Escalation of
+// MakeConfig if one is configured, or directly otherwise.
+func (ssh_conf *MakeConfig) privilegedWrite(path, content string) error {
+	if ssh_conf.Escalation != nil {
+		return ssh_conf.WriteFileSudo(path, []byte(content))
+	}
+	return ssh_conf.writeRemoteFile(path, content)
+}
+
+// privileged runs command with the Escalation of MakeConfig if one is
+// configured, or directly otherwise (ex. when connected as root).
+func (ssh_conf *MakeConfig) privileged(command string) error {
+	if _, stderr, err := ssh_conf.privilegedCapture(command); err != nil {
+		return remoteError(stderr, err)
+	}
+	return nil
+}
+
+// privilegedCapture works like privileged, but returns the stdout and stderr
+// of command.
+func (ssh_conf *MakeConfig) privilegedCapture(command string) (stdout []byte, stderr []byte, err error) {
+	if ssh_conf.Escalation != nil {
+		return ssh_conf.become(*ssh_conf.Escalation, command)
+	}
+	return ssh_conf.capture(command)
+}
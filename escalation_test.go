@@ -0,0 +1,69 @@
+package easyssh
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEscalationCommand(t *testing.T) {
+	tests := []struct {
+		escalation   Escalation
+		withPassword bool
+		expected     string
+	}{
+		{Escalation{}, false, `sudo -n -- sh -c 'id'`},
+		{Escalation{User: "www", Flags: []string{"-H"}}, true, `sudo -S -k -p '' -u 'www' '-H' -- sh -c 'id'`},
+		{Escalation{Method: EscalationDoas}, true, `doas -- sh -c 'id'`},
+		{Escalation{Method: EscalationDoas}, false, `doas -n -- sh -c 'id'`},
+		{Escalation{Method: EscalationSu}, true, `su 'root' -c 'id'`},
+		{Escalation{Method: EscalationSu, User: "www", Flags: []string{"-l"}}, true, `su '-l' 'www' -c 'id'`},
+	}
+
+	for _, test := range tests {
+		result, err := test.escalation.command("id", test.withPassword)
+		if err != nil {
+			t.Errorf("Error building command for %v: %s", test.escalation, err)
+		}
+		if result != test.expected {
+			t.Errorf("Expected %s, got %s", test.expected, result)
+		}
+	}
+
+	if _, err := (Escalation{Method: "pkexec"}).command("id", false); err == nil {
+		t.Errorf("Expected error for unknown method")
+	}
+}
+
+func TestEscalationPassword(t *testing.T) {
+	e := Escalation{PasswordFunc: func() (string, error) { return "secret", nil }}
+	if password, err := e.password(); err != nil || password != "secret" {
+		t.Errorf("Expected password from PasswordFunc, got '%s' (%v)", password, err)
+	}
+
+	e.Password = "fixed"
+	if password, _ := e.password(); password != "fixed" {
+		t.Errorf("Expected Password to take precedence, got '%s'", password)
+	}
+
+	failing := errors.New("no keyring")
+	e = Escalation{PasswordFunc: func() (string, error) { return "", failing }}
+	if _, err := e.password(); err != failing {
+		t.Errorf("Expected error of PasswordFunc, got %v", err)
+	}
+}
+
+func TestPromptWriter(t *testing.T) {
+	answered := 0
+	w := &promptWriter{prompt: defaultPromptRegexp, answer: func() { answered++ }}
+
+	w.Write([]byte("doas (john@example.com) pass"))
+	w.Write([]byte("word: "))
+	w.Write([]byte("\r\nuid=0(root)\r\nPassword: \r\n"))
+
+	if answered != 1 {
+		t.Errorf("Expected prompt to be answered once, got %d", answered)
+	}
+	if output := string(w.output()); output != "uid=0(root)\nPassword: \n" {
+		t.Errorf("Expected output following the prompt, got %q", output)
+	}
+}
@@ -0,0 +1,109 @@
+package easyssh
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// expectBufferSize limits the output kept for matching the rules of RunExpect.
+const expectBufferSize = 64 * 1024
+
+// ExpectRule answers a prompt: whenever the output of a command run by
+// RunExpect matches Pattern, Response is typed into the command's terminal.
+type ExpectRule struct {
+	Pattern  *regexp.Regexp
+	Response string
+}
+
+// Expect returns an ExpectRule typing response followed by a newline whenever
+// the regular expression pattern (ex. `\(yes/no\)\? $`) matches. It panics if
+// pattern is invalid, like regexp.MustCompile.
+func Expect(pattern, response string) ExpectRule {
+	return ExpectRule{Pattern: regexp.MustCompile(pattern), Response: response + "\n"}
+}
+
+// expectWriter passes output on to w and answers the rules matching the output
+// since the last answer.
+type expectWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	buf    []byte
+	rules  []ExpectRule
+	answer func(response string)
+}
+
+func (e *expectWriter) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.w != nil {
+		if _, err := e.w.Write(p); err != nil {
+			return 0, err
+		}
+	}
+
+	e.buf = append(e.buf, p...)
+	for matched := true; matched; {
+		matched = false
+		for _, rule := range e.rules {
+			if loc := rule.Pattern.FindIndex(e.buf); loc != nil {
+				e.buf = e.buf[loc[1]:]
+				e.answer(rule.Response)
+				matched = true
+				break
+			}
+		}
+	}
+	if len(e.buf) > expectBufferSize {
+		e.buf = e.buf[len(e.buf)-expectBufferSize:]
+	}
+
+	return len(p), nil
+}
+
+// RunExpect runs command in a terminal on the remote machine and answers
+// interactive prompts (ex. "Password:" or "(yes/no)?") according to rules:
+// Each time the output following the previous answer matches a rule, its
+// response is typed. The output, as written by the terminal, is copied to
+// output, which may be nil. It returns the exit status of the command like
+// RunTo.
+func (ssh_conf *MakeConfig) RunExpect(command string, output io.Writer, rules ...ExpectRule) (exitStatus int, err error) {
+	session, err := ssh_conf.connect()
+	if err != nil {
+		return -1, err
+	}
+	defer session.Close()
+
+	err = ssh_conf.request(session.Session, func() error {
+		return session.RequestPty("dumb", 24, 80, ssh.TerminalModes{ssh.ECHO: 0})
+	})
+	if err != nil {
+		return -1, fmt.Errorf("Error requesting pty: %s", err)
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return -1, err
+	}
+
+	responses := make(chan string, 16)
+	defer close(responses)
+	ssh_conf.spawn(func() {
+		for response := range responses {
+			io.WriteString(stdin, response)
+		}
+	})
+
+	w := &expectWriter{w: output, rules: rules, answer: func(response string) {
+		ssh_conf.logf(LogDebug2, "Answering prompt of '%s' on %s", command, ssh_conf.address())
+		responses <- response
+	}}
+	session.Stdout = w
+	session.Stderr = w
+
+	return exitStatusOf(ssh_conf.run(session.Session, command))
+}
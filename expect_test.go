@@ -0,0 +1,33 @@
+package easyssh
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestExpectWriter(t *testing.T) {
+	var out bytes.Buffer
+	var answers []string
+	w := &expectWriter{
+		w: &out,
+		rules: []ExpectRule{
+			Expect(`\(yes/no\)\? $`, "yes"),
+			Expect(`[Pp]assword: $`, "secret"),
+		},
+		answer: func(response string) { answers = append(answers, response) },
+	}
+
+	w.Write([]byte("Continue? (yes/"))
+	w.Write([]byte("no)? "))
+	w.Write([]byte("\r\nPassword: "))
+	w.Write([]byte("\r\nDone. Continue? (yes/no)? "))
+
+	expected := []string{"yes\n", "secret\n", "yes\n"}
+	if !reflect.DeepEqual(answers, expected) {
+		t.Errorf("Expected answers %q, got %q", expected, answers)
+	}
+	if out.String() != "Continue? (yes/no)? \r\nPassword: \r\nDone. Continue? (yes/no)? " {
+		t.Errorf("Expected output to be passed on, got %q", out.String())
+	}
+}
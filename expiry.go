@@ -0,0 +1,92 @@
+package easyssh
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrCredentialsExpired is matched by the *ExpiredError returned when
+// connecting with expired credentials.
+var ErrCredentialsExpired = errors.New("Credentials expired")
+
+// CredentialExpiry tells when a credential used for connecting expires.
+// Credential describes it (ex. "certificate deploy@ci" or "credentials").
+type CredentialExpiry struct {
+	Credential string
+	Expires    time.Time
+}
+
+// Expired returns true if the credential is no longer valid.
+func (e CredentialExpiry) Expired() bool {
+	return e.expiredAt(time.Now())
+}
+
+func (e CredentialExpiry) expiredAt(now time.Time) bool {
+	return !now.Before(e.Expires)
+}
+
+// ExpiredError is returned if a credential was used past its validity.
+type ExpiredError struct {
+	CredentialExpiry
+}
+
+func (e *ExpiredError) Error() string {
+	return fmt.Sprintf("%s: %s expired at %s", ErrCredentialsExpired, e.Credential, e.Expires.Format(time.RFC3339))
+}
+
+func (e *ExpiredError) Is(target error) bool {
+	return target == ErrCredentialsExpired
+}
+
+// expiries returns the expiry of CredentialsExpire and of all certificates
+// among signers.
+func (ssh_conf *MakeConfig) expiries(signers []ssh.Signer) []CredentialExpiry {
+	var result []CredentialExpiry
+
+	if !ssh_conf.CredentialsExpire.IsZero() {
+		result = append(result, CredentialExpiry{Credential: "credentials", Expires: ssh_conf.CredentialsExpire})
+	}
+
+	for _, signer := range signers {
+		cert, ok := signer.PublicKey().(*ssh.Certificate)
+		if !ok || cert.ValidBefore == ssh.CertTimeInfinity {
+			continue
+		}
+		result = append(result, CredentialExpiry{
+			Credential: "certificate " + cert.KeyId,
+			Expires:    time.Unix(int64(cert.ValidBefore), 0),
+		})
+	}
+
+	return result
+}
+
+// checkExpiry passes credentials which expire within ExpiryWarning or already
+// have expired to ExpiryCallback, which decides whether to use them anyway.
+// Without callback, expired credentials are refused and soon expiring ones
+// logged.
+func (ssh_conf *MakeConfig) checkExpiry(signers []ssh.Signer) error {
+	now := ssh_conf.clock().Now()
+	for _, expiry := range ssh_conf.expiries(signers) {
+		if !expiry.expiredAt(now) && expiry.Expires.Sub(now) > ssh_conf.ExpiryWarning {
+			continue
+		}
+
+		if ssh_conf.ExpiryCallback != nil {
+			if err := ssh_conf.ExpiryCallback(expiry); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if expiry.expiredAt(now) {
+			return &ExpiredError{expiry}
+		}
+		ssh_conf.logf(LogInfo, "Warning: %s for %s expires at %s", expiry.Credential, ssh_conf.address(), expiry.Expires.Format(time.RFC3339))
+	}
+
+	return nil
+}
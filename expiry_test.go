@@ -0,0 +1,79 @@
+package easyssh
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// returns a signer presenting a certificate valid until validBefore
+func newTestCertSigner(t *testing.T, validBefore time.Time) ssh.Signer {
+	signer := newTestSigner(t)
+	cert := &ssh.Certificate{
+		Key:         signer.PublicKey(),
+		KeyId:       "deploy",
+		CertType:    ssh.UserCert,
+		ValidBefore: uint64(validBefore.Unix()),
+	}
+	if err := cert.SignCert(rand.Reader, newTestSigner(t)); err != nil {
+		t.Fatalf("Error signing certificate: %s", err)
+	}
+	certSigner, err := ssh.NewCertSigner(cert, signer)
+	if err != nil {
+		t.Fatalf("Error creating signer: %s", err)
+	}
+	return certSigner
+}
+
+func TestCheckExpiry(t *testing.T) {
+	cfg := &MakeConfig{}
+	valid := newTestCertSigner(t, time.Now().Add(time.Hour))
+	expired := newTestCertSigner(t, time.Now().Add(-time.Minute))
+
+	if err := cfg.checkExpiry([]ssh.Signer{valid, newTestSigner(t)}); err != nil {
+		t.Errorf("Expected valid certificate to be accepted, got %s", err)
+	}
+
+	err := cfg.checkExpiry([]ssh.Signer{expired})
+	var expiredErr *ExpiredError
+	if !errors.Is(err, ErrCredentialsExpired) || !errors.As(err, &expiredErr) || expiredErr.Credential != "certificate deploy" {
+		t.Errorf("Expected expired certificate to be refused, got %v", err)
+	}
+
+	cfg.CredentialsExpire = time.Now().Add(-time.Second)
+	if err := cfg.checkExpiry(nil); !errors.Is(err, ErrCredentialsExpired) {
+		t.Errorf("Expected expired credentials to be refused, got %v", err)
+	}
+}
+
+func TestExpiryCallback(t *testing.T) {
+	var seen []CredentialExpiry
+	cfg := &MakeConfig{
+		ExpiryWarning: 2 * time.Hour,
+		ExpiryCallback: func(expiry CredentialExpiry) error {
+			seen = append(seen, expiry)
+			return nil
+		},
+	}
+
+	signers := []ssh.Signer{
+		newTestCertSigner(t, time.Now().Add(time.Hour)),
+		newTestCertSigner(t, time.Now().Add(-time.Hour)),
+		newTestCertSigner(t, time.Now().Add(24*time.Hour)),
+	}
+	if err := cfg.checkExpiry(signers); err != nil {
+		t.Errorf("Expected callback to accept credentials, got %s", err)
+	}
+	if len(seen) != 2 || seen[0].Expired() || !seen[1].Expired() {
+		t.Errorf("Expected callback for expiring and expired certificate, got %v", seen)
+	}
+
+	refused := errors.New("refused")
+	cfg.ExpiryCallback = func(CredentialExpiry) error { return refused }
+	if err := cfg.checkExpiry(signers); err != refused {
+		t.Errorf("Expected error of callback, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package easyssh
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Facts are details of a remote machine, as returned by GatherFacts. Fields
+// which could not be determined are left zero.
+type Facts struct {
+	Hostname string
+	OS       OSInfo
+	CPUs     int
+	// Memory is the total physical memory in bytes.
+	Memory uint64
+	Uptime time.Duration
+	// Shell is the login shell of the user (ex. "/bin/bash").
+	Shell string
+}
+
+// factsCommand prints the facts one per line on Linux and macOS, followed by
+// the output of osCommand.
+const factsCommand = `uname -n; ` +
+	`echo "$({ nproc || getconf _NPROCESSORS_ONLN || sysctl -n hw.ncpu; } 2>/dev/null)"; ` +
+	`echo "$({ awk '/^MemTotal:/ { printf "%.0f\n", $2 * 1024 }' /proc/meminfo || sysctl -n hw.memsize; } 2>/dev/null)"; ` +
+	`echo "$({ cut -d ' ' -f 1 /proc/uptime || sysctl -n kern.boottime; } 2>/dev/null)"; ` +
+	`echo "$SHELL"; ` + osCommand
+
+// factsLines is the number of lines printed by factsCommand before osCommand.
+const factsLines = 5
+
+var bootTimeRegex = regexp.MustCompile(`sec = (\d+)`)
+
+// GatherFacts returns details of the remote machine like its hostname, OS,
+// number of CPUs, memory and uptime, all collected by a single command.
+func (ssh_conf *MakeConfig) GatherFacts() (*Facts, error) {
+	stdout, stderr, err := ssh_conf.capture(factsCommand)
+	if err != nil {
+		return nil, remoteError(stderr, err)
+	}
+
+	lines := strings.SplitN(string(stdout), "\n", factsLines+1)
+	if len(lines) <= factsLines {
+		return nil, errors.New("Error gathering facts: too little output")
+	}
+	for i := range lines[:factsLines] {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	info, err := parseOS(lines[factsLines])
+	if err != nil {
+		return nil, err
+	}
+
+	facts := &Facts{Hostname: lines[0], OS: *info, Shell: lines[4]}
+	facts.CPUs, _ = strconv.Atoi(lines[1])
+	facts.Memory, _ = strconv.ParseUint(lines[2], 10, 64)
+	if m := bootTimeRegex.FindStringSubmatch(lines[3]); m != nil {
+		// macOS reports the boot time (ex. "{ sec = 1712345678, usec = 0 } ...")
+		if sec, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+			facts.Uptime = ssh_conf.clock().Now().Sub(time.Unix(sec, 0)).Truncate(time.Second)
+		}
+	} else if seconds, err := strconv.ParseFloat(lines[3], 64); err == nil {
+		facts.Uptime = time.Duration(seconds * float64(time.Second))
+	}
+
+	return facts, nil
+}
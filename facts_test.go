@@ -0,0 +1,68 @@
+package easyssh
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
+)
+
+func TestGatherFacts(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.Handle(factsCommand, testserver.Reply(`web1
+8
+16777216000
+3600.52
+/bin/bash
+Linux
+6.1.0-18-amd64
+x86_64
+PRETTY_NAME="Debian GNU/Linux 12 (bookworm)"
+VERSION_ID="12"
+ID=debian
+`, 0))
+
+	facts, err := cfg.GatherFacts()
+	if err != nil {
+		t.Fatalf("Error gathering facts: %s", err)
+	}
+	expected := &Facts{
+		Hostname: "web1",
+		OS: OSInfo{Kernel: "Linux", Release: "6.1.0-18-amd64", Arch: "x86_64", ID: "debian", VersionID: "12",
+			Name: "Debian GNU/Linux 12 (bookworm)"},
+		CPUs:   8,
+		Memory: 16777216000,
+		Uptime: 3600*time.Second + 520*time.Millisecond,
+		Shell:  "/bin/bash",
+	}
+	if !reflect.DeepEqual(facts, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, facts)
+	}
+}
+
+func TestGatherFactsMacOS(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.Handle(factsCommand, testserver.Reply(`mac
+10
+17179869184
+{ sec = 1000, usec = 0 } Thu Jan  1 00:16:40 1970
+
+Darwin
+23.4.0
+arm64
+`, 0))
+	cfg.Clock = &fakeClock{now: time.Unix(4600, 0)}
+
+	facts, err := cfg.GatherFacts()
+	if err != nil {
+		t.Fatalf("Error gathering facts: %s", err)
+	}
+	expected := &Facts{Hostname: "mac", OS: OSInfo{Kernel: "Darwin", Release: "23.4.0", Arch: "arm64"},
+		CPUs: 10, Memory: 17179869184, Uptime: time.Hour}
+	if !reflect.DeepEqual(facts, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, facts)
+	}
+}
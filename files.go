@@ -0,0 +1,155 @@
+package easyssh
+
+import (
+	"errors"
+	"os"
+	"path"
+
+	"github.com/pkg/sftp"
+)
+
+// withSFTP connects to the remote machine and calls f with an SFTP client,
+// closing both afterwards.
+func (ssh_conf *MakeConfig) withSFTP(f func(*sftp.Client) error) error {
+	client, err := ssh_conf.Connect()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	sftpClient, err := sftp.NewClient(client.client)
+	if err != nil {
+		return err
+	}
+	defer sftpClient.Close()
+
+	return f(sftpClient)
+}
+
+// fileError returns err of op on the remote file name as an *os.PathError.
+func fileError(op, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return &os.PathError{Op: op, Path: name, Err: err}
+}
+
+// Mkdir creates the directory name on the remote machine with the permissions
+// perm (which are not subject to the umask). The parent directory needs to
+// exist.
+func (ssh_conf *MakeConfig) Mkdir(name string, perm os.FileMode) error {
+	return ssh_conf.withSFTP(func(c *sftp.Client) error {
+		return mkdir(c, name, perm)
+	})
+}
+
+// MkdirAll creates the directory name on the remote machine along with any
+// missing parents, all with the permissions perm. It does nothing if name
+// already is a directory.
+func (ssh_conf *MakeConfig) MkdirAll(name string, perm os.FileMode) error {
+	return ssh_conf.withSFTP(func(c *sftp.Client) error {
+		return mkdirAll(c, name, perm)
+	})
+}
+
+func mkdir(c *sftp.Client, name string, perm os.FileMode) error {
+	if err := c.Mkdir(name); err != nil {
+		return fileError("mkdir", name, err)
+	}
+	return fileError("chmod", name, c.Chmod(name, perm))
+}
+
+func mkdirAll(c *sftp.Client, name string, perm os.FileMode) error {
+	info, err := c.Stat(name)
+	if err == nil {
+		if info.IsDir() {
+			return nil
+		}
+		return &os.PathError{Op: "mkdir", Path: name, Err: errors.New("Not a directory")}
+	}
+
+	if parent := path.Dir(path.Clean(name)); parent != "/" && parent != "." {
+		if err := mkdirAll(c, parent, perm); err != nil {
+			return err
+		}
+	}
+	return mkdir(c, name, perm)
+}
+
+// Remove removes the file or empty directory name on the remote machine.
+func (ssh_conf *MakeConfig) Remove(name string) error {
+	return ssh_conf.withSFTP(func(c *sftp.Client) error {
+		return fileError("remove", name, c.Remove(name))
+	})
+}
+
+// RemoveAll removes name on the remote machine along with everything it
+// contains. Symbolic links are removed, not followed. It returns nil if name
+// does not exist.
+func (ssh_conf *MakeConfig) RemoveAll(name string) error {
+	return ssh_conf.withSFTP(func(c *sftp.Client) error {
+		return removeAll(c, name)
+	})
+}
+
+func removeAll(c *sftp.Client, name string) error {
+	info, err := c.Lstat(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fileError("remove", name, err)
+	}
+
+	if info.IsDir() {
+		entries, err := c.ReadDir(name)
+		if err != nil {
+			return fileError("remove", name, err)
+		}
+		for _, entry := range entries {
+			if err := removeAll(c, path.Join(name, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return fileError("remove", name, c.Remove(name))
+}
+
+// Chmod changes the permissions of name on the remote machine to mode.
+func (ssh_conf *MakeConfig) Chmod(name string, mode os.FileMode) error {
+	return ssh_conf.withSFTP(func(c *sftp.Client) error {
+		return fileError("chmod", name, c.Chmod(name, mode))
+	})
+}
+
+// Chown changes the numeric user and group ID of name on the remote machine.
+// Changing the owner usually requires being connected as root.
+func (ssh_conf *MakeConfig) Chown(name string, uid, gid int) error {
+	return ssh_conf.withSFTP(func(c *sftp.Client) error {
+		return fileError("chown", name, c.Chown(name, uid, gid))
+	})
+}
+
+// Symlink creates newname on the remote machine as a symbolic link to
+// oldname.
+func (ssh_conf *MakeConfig) Symlink(oldname, newname string) error {
+	return ssh_conf.withSFTP(func(c *sftp.Client) error {
+		return fileError("symlink", newname, c.Symlink(oldname, newname))
+	})
+}
+
+// Stat returns information about the file name on the remote machine,
+// following symbolic links. If it does not exist, the error matches
+// os.ErrNotExist.
+func (ssh_conf *MakeConfig) Stat(name string) (os.FileInfo, error) {
+	var info os.FileInfo
+	err := ssh_conf.withSFTP(func(c *sftp.Client) (err error) {
+		info, err = c.Stat(name)
+		return fileError("stat", name, err)
+	})
+	return info, err
+}
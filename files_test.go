@@ -0,0 +1,60 @@
+package easyssh
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestFileManagement(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+
+	if err := cfg.MkdirAll("/srv/app/releases", 0755); err != nil {
+		t.Fatalf("Error creating directories: %s", err)
+	}
+	if err := cfg.MkdirAll("/srv/app/releases", 0755); err != nil {
+		t.Errorf("Expected MkdirAll to succeed for an existing directory, got %s", err)
+	}
+	if err := cfg.Mkdir("/srv/app/releases/v1", 0755); err != nil {
+		t.Fatalf("Error creating directory: %s", err)
+	}
+	if err := srv.WriteFile("/srv/app/releases/v1/app.conf", []byte("debug = false\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Chmod("/srv/app/releases/v1/app.conf", 0600); err != nil {
+		t.Errorf("Error changing permissions: %s", err)
+	}
+	if err := cfg.Symlink("/srv/app/releases/v1", "/srv/app/current"); err != nil {
+		t.Errorf("Error creating symlink: %s", err)
+	}
+
+	info, err := cfg.Stat("/srv/app/releases/v1/app.conf")
+	if err != nil {
+		t.Fatalf("Error getting file info: %s", err)
+	}
+	if info.Name() != "app.conf" || info.Size() != 14 || info.IsDir() {
+		t.Errorf("Expected a file app.conf of 14 bytes, got %s (%d bytes)", info.Name(), info.Size())
+	}
+
+	if err := cfg.Mkdir("/srv/app/releases/v1", 0755); err == nil {
+		t.Errorf("Expected an error creating an existing directory")
+	}
+	if err := cfg.Remove("/srv/app/releases"); err == nil {
+		t.Errorf("Expected an error removing a non-empty directory")
+	}
+
+	if err := cfg.RemoveAll("/srv/app"); err != nil {
+		t.Fatalf("Error removing directory: %s", err)
+	}
+	if err := cfg.RemoveAll("/srv/app"); err != nil {
+		t.Errorf("Expected RemoveAll to succeed for a missing directory, got %s", err)
+	}
+	var pathErr *os.PathError
+	if _, err := cfg.Stat("/srv/app"); !errors.Is(err, os.ErrNotExist) || !errors.As(err, &pathErr) || pathErr.Path != "/srv/app" {
+		t.Errorf("Expected an *os.PathError matching os.ErrNotExist, got %v", err)
+	}
+	if err := cfg.Remove("/srv/app"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist, got %v", err)
+	}
+}
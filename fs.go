@@ -0,0 +1,188 @@
+package easyssh
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+
+	"github.com/pkg/sftp"
+)
+
+// RemoteFS is a read-only fs.FS of a directory on the remote machine, accessed
+// using SFTP. This allows using functions like fs.WalkDir, template.ParseFS or
+// http.FS with remote files. It needs to be closed after use.
+type RemoteFS struct {
+	root   string
+	client *Client
+	sftp   *sftp.Client
+}
+
+var (
+	_ fs.StatFS     = (*RemoteFS)(nil)
+	_ fs.ReadDirFS  = (*RemoteFS)(nil)
+	_ fs.ReadFileFS = (*RemoteFS)(nil)
+)
+
+// FS connects to the remote machine and returns the directory dir as a
+// RemoteFS. Names passed to it are relative to dir, which may be "/" for the
+// whole filesystem.
+func (ssh_conf *MakeConfig) FS(dir string) (*RemoteFS, error) {
+	client, err := ssh_conf.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	sftpClient, err := sftp.NewClient(client.client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &RemoteFS{root: dir, client: client, sftp: sftpClient}, nil
+}
+
+// Close closes the connection to the remote machine.
+func (rfs *RemoteFS) Close() error {
+	rfs.sftp.Close()
+	return rfs.client.Close()
+}
+
+// path returns the remote path of name, which needs to be valid for fs.FS.
+func (rfs *RemoteFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return path.Join(rfs.root, name), nil
+}
+
+// pathError returns err as an *fs.PathError for name instead of the remote
+// path.
+func pathError(op, name string, err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// Open opens the named file or directory for reading.
+func (rfs *RemoteFS) Open(name string) (fs.File, error) {
+	p, err := rfs.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := rfs.sftp.Stat(p)
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	if info.IsDir() {
+		return &remoteDir{fs: rfs, name: name, path: p, info: info}, nil
+	}
+
+	f, err := rfs.sftp.Open(p)
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	return f, nil
+}
+
+// Stat returns information about the named file, following symlinks.
+func (rfs *RemoteFS) Stat(name string) (fs.FileInfo, error) {
+	p, err := rfs.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := rfs.sftp.Stat(p)
+	if err != nil {
+		return nil, pathError("stat", name, err)
+	}
+	return info, nil
+}
+
+// ReadDir returns the entries of the named directory sorted by name.
+func (rfs *RemoteFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	p, err := rfs.path("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	infos, err := rfs.sftp.ReadDir(p)
+	if err != nil {
+		return nil, pathError("readdir", name, err)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = fs.FileInfoToDirEntry(info)
+	}
+	return entries, nil
+}
+
+// ReadFile returns the content of the named file.
+func (rfs *RemoteFS) ReadFile(name string) ([]byte, error) {
+	f, err := rfs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, ok := f.(*remoteDir); ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	return io.ReadAll(f)
+}
+
+// remoteDir is an open directory of a RemoteFS. Its entries are read on the
+// first call of ReadDir.
+type remoteDir struct {
+	fs      *RemoteFS
+	name    string
+	path    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *remoteDir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *remoteDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *remoteDir) Close() error {
+	return nil
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *remoteDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.fs.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+		d.read = true
+	}
+
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
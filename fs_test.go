@@ -0,0 +1,47 @@
+package easyssh
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
+)
+
+func TestRemoteFS(t *testing.T) {
+	srv, err := testserver.New("test", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	srv.Mkdir("/srv")
+	srv.Mkdir("/srv/www")
+	srv.WriteFile("/srv/www/index.html", []byte("<h1>Hello</h1>\n"))
+	srv.Mkdir("/srv/www/css")
+	srv.WriteFile("/srv/www/css/site.css", []byte("h1 {}\n"))
+
+	cfg := &MakeConfig{User: "test", Password: "secret", Server: srv.Host(), Port: srv.Port(),
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey())}
+	rfs, err := cfg.FS("/srv/www")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rfs.Close()
+
+	if err := fstest.TestFS(rfs, "index.html", "css/site.css"); err != nil {
+		t.Error(err)
+	}
+
+	if content, err := fs.ReadFile(rfs, "css/site.css"); err != nil || string(content) != "h1 {}\n" {
+		t.Errorf("Expected file content, got %q (%v)", content, err)
+	}
+	if _, err := rfs.Open("../etc/passwd"); err == nil {
+		t.Errorf("Expected invalid path to be rejected")
+	}
+	if _, err := rfs.Stat("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
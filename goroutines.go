@@ -0,0 +1,114 @@
+package easyssh
+
+import (
+	"context"
+	"sync"
+)
+
+// goroutineTracker keeps count of running background goroutines, so callers
+// can wait for all of them to exit.
+type goroutineTracker struct {
+	mu    sync.Mutex
+	count int
+	idle  chan struct{}
+}
+
+func (t *goroutineTracker) add() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.count == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.count++
+}
+
+func (t *goroutineTracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.count--
+	if t.count == 0 {
+		close(t.idle)
+	}
+}
+
+// running returns the number of tracked goroutines which have not exited yet.
+func (t *goroutineTracker) running() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.count
+}
+
+// wait blocks until no tracked goroutines are running anymore or ctx is done.
+func (t *goroutineTracker) wait(ctx context.Context) error {
+	t.mu.Lock()
+	if t.count == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// background tracks the goroutines started by all MakeConfigs.
+var background goroutineTracker
+
+// lazyInit guards the lazy creation of internal state of MakeConfigs.
+var lazyInit sync.Mutex
+
+// returns the tracker for the goroutines started on behalf of MakeConfig
+func (ssh_conf *MakeConfig) goroutines() *goroutineTracker {
+	lazyInit.Lock()
+	defer lazyInit.Unlock()
+
+	if ssh_conf.tracker == nil {
+		ssh_conf.tracker = &goroutineTracker{}
+	}
+	return ssh_conf.tracker
+}
+
+// spawn runs f in a new goroutine which is accounted for by WaitIdle.
+func (ssh_conf *MakeConfig) spawn(f func()) {
+	tracker := ssh_conf.goroutines()
+	background.add()
+	tracker.add()
+
+	go func() {
+		defer background.done()
+		defer tracker.done()
+		f()
+	}()
+}
+
+// WaitIdle blocks until all background goroutines started on behalf of
+// MakeConfig (ex. stream readers or upload writers) have exited, or until ctx
+// is done. This allows tests and daemons to verify nothing was leaked.
+func (ssh_conf *MakeConfig) WaitIdle(ctx context.Context) error {
+	return ssh_conf.goroutines().wait(ctx)
+}
+
+// WaitIdle blocks until all background goroutines started on behalf of the
+// hosts of the group have exited, or until ctx is done.
+func (g *Group) WaitIdle(ctx context.Context) error {
+	for _, host := range g.Hosts {
+		if err := host.WaitIdle(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitIdle blocks until all background goroutines started by easyssh have
+// exited, or until ctx is done.
+func WaitIdle(ctx context.Context) error {
+	return background.wait(ctx)
+}
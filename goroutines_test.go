@@ -0,0 +1,39 @@
+package easyssh
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWaitIdle(t *testing.T) {
+	cfg := &MakeConfig{}
+
+	if err := cfg.WaitIdle(context.Background()); err != nil {
+		t.Errorf("Expected no error waiting without goroutines, got %s", err)
+	}
+
+	release := make(chan struct{})
+	cfg.spawn(func() { <-release })
+	cfg.spawn(func() { <-release })
+
+	if n := cfg.goroutines().running(); n != 2 {
+		t.Errorf("Expected 2 running goroutines, got %d", n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := cfg.WaitIdle(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline to be exceeded while goroutines are running, got %v", err)
+	}
+
+	close(release)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := cfg.WaitIdle(ctx); err != nil {
+		t.Errorf("Expected goroutines to exit, got %s", err)
+	}
+	if err := WaitIdle(ctx); err != nil {
+		t.Errorf("Expected all goroutines to exit, got %s", err)
+	}
+}
@@ -0,0 +1,173 @@
+package easyssh
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Group is a set of remote machines which commands can be run on together.
+// Hosts using the same JumpHost share a single connection to it while a
+// command is run on the group.
+// Concurrency limits the number of hosts worked on at the same time. If it is
+// zero, all hosts are worked on at once.
+type Group struct {
+	Hosts       []*MakeConfig
+	Concurrency int
+}
+
+// HostResult is the outcome of an operation on a single host of a Group,
+// carrying the Labels of the host.
+// Err is set if the operation could not be completed, a command which ran but
+// failed is only reported through its ExitStatus.
+type HostResult struct {
+	Host       *MakeConfig
+	Labels     map[string]string
+	Command    string
+	Duration   time.Duration
+	Stdout     string
+	Stderr     string
+	ExitStatus int
+	Latency    *LatencyResult
+	Workflow   *WorkflowReport
+	Err        error
+}
+
+// NewGroup returns a Group consisting of the given hosts.
+func NewGroup(hosts ...*MakeConfig) *Group {
+	return &Group{Hosts: hosts}
+}
+
+// Run runs command on all hosts of the group in parallel and returns the
+// results in the order of Hosts.
+func (g *Group) Run(command string) Results {
+	defer g.shareJumpHosts()()
+
+	return g.forEach(func(host *MakeConfig) HostResult {
+		stdout, stderr, err := host.capture(command)
+		status, err := exitStatusOf(err)
+		return HostResult{
+			Host:       host,
+			Labels:     host.Labels,
+			Command:    command,
+			Stdout:     string(stdout),
+			Stderr:     string(stderr),
+			ExitStatus: status,
+			Err:        err,
+		}
+	})
+}
+
+// Upload uploads the local file sourceFile to targetFile on all hosts of the
+// group in parallel and returns the results in the order of Hosts.
+func (g *Group) Upload(sourceFile, targetFile string) Results {
+	defer g.shareJumpHosts()()
+
+	return g.forEach(func(host *MakeConfig) HostResult {
+		err := host.Upload(sourceFile, targetFile)
+		return HostResult{Host: host, Labels: host.Labels, Err: err}
+	})
+}
+
+// Latency measures the latency of all hosts of the group in parallel and
+// returns the results in the order of Hosts.
+func (g *Group) Latency() Results {
+	defer g.shareJumpHosts()()
+
+	return g.forEach(func(host *MakeConfig) HostResult {
+		latency, err := host.Latency()
+		return HostResult{Host: host, Labels: host.Labels, Latency: latency, Err: err}
+	})
+}
+
+// forEach calls f for all hosts of the group using at most Concurrency
+// goroutines at once and collects the results in the order of Hosts, with
+// their Duration set.
+func (g *Group) forEach(f func(host *MakeConfig) HostResult) Results {
+	results := make(Results, len(g.Hosts))
+
+	workers := g.Concurrency
+	if workers <= 0 || workers > len(g.Hosts) {
+		workers = len(g.Hosts)
+	}
+	slots := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+	for i, host := range g.Hosts {
+		i, host := i, host
+		slots <- struct{}{}
+		wg.Add(1)
+		host.spawn(func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			start := host.clock().Now()
+			results[i] = f(host)
+			results[i].Duration = host.clock().Now().Sub(start)
+		})
+	}
+	wg.Wait()
+
+	return results
+}
+
+// RunAny runs command on the hosts of the group one after another until it
+// succeeds on one of them and returns that host together with the command's
+// stdout. This is useful for querying a cluster where any member can answer.
+func (g *Group) RunAny(command string) (host *MakeConfig, output string, err error) {
+	if len(g.Hosts) == 0 {
+		return nil, "", errors.New("No hosts in group")
+	}
+	defer g.shareJumpHosts()()
+
+	var errs []string
+	for _, host := range g.Hosts {
+		stdout, _, err := host.capture(command)
+		if err == nil {
+			return host, string(stdout), nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", host.Server, err))
+	}
+
+	return nil, "", fmt.Errorf("Command failed on all hosts: %s", strings.Join(errs, "; "))
+}
+
+// RunAnyParallel works like RunAny, but runs command on all hosts at the same
+// time. As soon as it succeeded on one of them, the sessions to the other hosts
+// are closed.
+func (g *Group) RunAnyParallel(command string) (host *MakeConfig, output string, err error) {
+	if len(g.Hosts) == 0 {
+		return nil, "", errors.New("No hosts in group")
+	}
+	defer g.shareJumpHosts()()
+
+	type result struct {
+		host   *MakeConfig
+		stdout []byte
+		err    error
+	}
+
+	results := make(chan result, len(g.Hosts))
+	cancel := make(chan struct{})
+	defer close(cancel)
+
+	for _, host := range g.Hosts {
+		host := host
+		host.spawn(func() {
+			stdout, _, err := host.captureUntil(command, nil, cancel)
+			results <- result{host, stdout, err}
+		})
+	}
+
+	var errs []string
+	for range g.Hosts {
+		r := <-results
+		if r.err == nil {
+			return r.host, string(r.stdout), nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", r.host.Server, r.err))
+	}
+
+	return nil, "", fmt.Errorf("Command failed on all hosts: %s", strings.Join(errs, "; "))
+}
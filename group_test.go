@@ -0,0 +1,107 @@
+package easyssh
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
+)
+
+func TestGroupForEachConcurrency(t *testing.T) {
+	g := NewGroup(&MakeConfig{Server: "a"}, &MakeConfig{Server: "b"}, &MakeConfig{Server: "c"},
+		&MakeConfig{Server: "d"}, &MakeConfig{Server: "e"})
+	g.Concurrency = 2
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+
+	results := g.forEach(func(host *MakeConfig) HostResult {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return HostResult{Host: host, Stdout: host.Server}
+	})
+
+	if maxRunning > 2 {
+		t.Errorf("Expected at most 2 hosts to be worked on at once, got %d", maxRunning)
+	}
+	if len(results) != len(g.Hosts) {
+		t.Fatalf("Expected %d results, got %d", len(g.Hosts), len(results))
+	}
+	for i, result := range results {
+		if result.Host != g.Hosts[i] || result.Stdout != g.Hosts[i].Server {
+			t.Errorf("Expected result %d to belong to host %s, got %s", i, g.Hosts[i].Server, result.Stdout)
+		}
+	}
+}
+
+func TestGroupRunAny(t *testing.T) {
+	failing, first := echoTestServer(t)
+	defer failing.Close()
+	failing.HandleDefault(testserver.Reply("", 1))
+	answering, second := echoTestServer(t)
+	defer answering.Close()
+	unused, third := echoTestServer(t)
+	defer unused.Close()
+
+	host, output, err := NewGroup(first, second, third).RunAny("hostname")
+	if err != nil || host != second || output != "hostname\n" {
+		t.Errorf("Expected output of second host, got %v %q (%v)", host, output, err)
+	}
+	if commands := unused.Commands(); len(commands) != 0 {
+		t.Errorf("Expected no commands on third host, got %v", commands)
+	}
+
+	answering.HandleDefault(testserver.Reply("", 2))
+	if _, _, err := NewGroup(first, second).RunAny("hostname"); err == nil ||
+		!strings.Contains(err.Error(), "exit status 1") || !strings.Contains(err.Error(), "exit status 2") {
+		t.Errorf("Expected errors of all hosts, got %v", err)
+	}
+}
+
+func TestGroupRunAnyParallel(t *testing.T) {
+	slow, first := echoTestServer(t)
+	defer slow.Close()
+	cancelled := make(chan struct{})
+	slow.HandleDefault(func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		// keeps running until the session is closed by the client
+		for {
+			if _, err := io.WriteString(stdout, "."); err != nil {
+				close(cancelled)
+				return 0
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+	fast, second := echoTestServer(t)
+	defer fast.Close()
+
+	host, output, err := NewGroup(first, second).RunAnyParallel("hostname")
+	if err != nil || host != second || output != "hostname\n" {
+		t.Errorf("Expected output of second host, got %v %q (%v)", host, output, err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected session of the slower host to be closed")
+	}
+
+	fast.HandleDefault(testserver.Reply("", 1))
+	slow.HandleDefault(testserver.Reply("", 2))
+	if _, _, err := NewGroup(first, second).RunAnyParallel("hostname"); err == nil ||
+		!strings.Contains(err.Error(), "exit status 1") || !strings.Contains(err.Error(), "exit status 2") {
+		t.Errorf("Expected errors of all hosts, got %v", err)
+	}
+}
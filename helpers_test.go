@@ -0,0 +1,38 @@
+package easyssh
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
+)
+
+// echoTestServer starts a testserver echoing every command run on it and
+// returns a MakeConfig connecting to it.
+func echoTestServer(t *testing.T) (*testserver.Server, *MakeConfig) {
+	srv, err := testserver.New("test", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.HandleDefault(func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		fmt.Fprintln(stdout, command)
+		return 0
+	})
+	cfg := &MakeConfig{User: "test", Password: "secret", Server: srv.Host(), Port: srv.Port(), InMemory: true,
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey())}
+	return srv, cfg
+}
+
+// connectTestServer starts a testserver like echoTestServer and returns a
+// Client connected to it.
+func connectTestServer(t *testing.T) (*testserver.Server, *Client) {
+	srv, cfg := echoTestServer(t)
+	client, err := cfg.Connect()
+	if err != nil {
+		srv.Close()
+		t.Fatal(err)
+	}
+	return srv, client
+}
@@ -0,0 +1,127 @@
+package easyssh
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// UnknownHostKeyCallback is called by TrustOnFirstUse for host keys not found in
+// the known_hosts file. It decides whether key is trusted for hostname, and
+// whether it should be added to the known_hosts file.
+type UnknownHostKeyCallback func(hostname string, remote net.Addr, key ssh.PublicKey) (trust bool, persist bool, err error)
+
+// TrustOnFirstUse returns a HostKeyCallback checking host keys against the
+// known_hosts file at path, which is created if missing. For unknown hosts,
+// unknown decides whether to trust the key. Keys not matching a known key of
+// the host are always rejected.
+func TrustOnFirstUse(path string, unknown UnknownHostKeyCallback) (ssh.HostKeyCallback, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("Error creating directory for '%s': %s", path, err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening '%s': %s", path, err)
+	}
+	f.Close()
+
+	known, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading '%s': %s", path, err)
+	}
+
+	var mu sync.Mutex
+	trusted := map[string][]ssh.PublicKey{}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := known(hostname, remote, key)
+
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, k := range trusted[hostname] {
+			if bytes.Equal(k.Marshal(), key.Marshal()) {
+				return nil
+			}
+		}
+
+		trust, persist, cbErr := unknown(hostname, remote, key)
+		if cbErr != nil {
+			return cbErr
+		}
+		if !trust {
+			return err
+		}
+
+		if persist {
+			if err := appendKnownHost(path, hostname, key); err != nil {
+				return err
+			}
+		}
+		trusted[hostname] = append(trusted[hostname], key)
+
+		return nil
+	}, nil
+}
+
+// appends a line for key of hostname to the known_hosts file at path
+func appendKnownHost(path, hostname string, key ssh.PublicKey) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("Error opening '%s': %s", path, err)
+	}
+	defer f.Close()
+
+	line := knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)
+	if _, err := io.WriteString(f, line+"\n"); err != nil {
+		return fmt.Errorf("Error writing '%s': %s", path, err)
+	}
+
+	return nil
+}
+
+// PromptHostKey returns an UnknownHostKeyCallback asking the user on out whether
+// to continue connecting, like the OpenSSH client does, and reading the answer
+// from in. Accepted keys are added to the known_hosts file.
+func PromptHostKey(in io.Reader, out io.Writer) UnknownHostKeyCallback {
+	reader := bufio.NewReader(in)
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) (bool, bool, error) {
+		host := hostname
+		if remote != nil && remote.String() != hostname {
+			host += " (" + remote.String() + ")"
+		}
+		fmt.Fprintf(out, "The authenticity of host '%s' can't be established.\n", host)
+		fmt.Fprintf(out, "%s key fingerprint is %s.\n", key.Type(), ssh.FingerprintSHA256(key))
+		fmt.Fprint(out, "Are you sure you want to continue connecting (yes/no)? ")
+
+		for {
+			answer, err := reader.ReadString('\n')
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "yes":
+				return true, true, nil
+			case "no":
+				return false, false, nil
+			}
+			if err != nil {
+				return false, false, fmt.Errorf("Error reading answer: %s", err)
+			}
+			fmt.Fprint(out, "Please type 'yes' or 'no': ")
+		}
+	}
+}
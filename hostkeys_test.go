@@ -0,0 +1,89 @@
+package easyssh
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestTrustOnFirstUse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ssh", "known_hosts")
+	key := newTestPublicKey(t)
+	remote := &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 22}
+
+	asked := 0
+	callback, err := TrustOnFirstUse(path, func(hostname string, remote net.Addr, key ssh.PublicKey) (bool, bool, error) {
+		asked++
+		return true, true, nil
+	})
+	if err != nil {
+		t.Fatalf("Error creating callback: %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := callback("example.com:22", remote, key); err != nil {
+			t.Errorf("Expected key to be trusted, got %s", err)
+		}
+	}
+	if asked != 1 {
+		t.Errorf("Expected to be asked once, got %d", asked)
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	if !strings.HasPrefix(string(data), "example.com ") {
+		t.Errorf("Expected key to be persisted, got %q", data)
+	}
+
+	reread, err := TrustOnFirstUse(path, func(string, net.Addr, ssh.PublicKey) (bool, bool, error) {
+		t.Errorf("Expected known key not to be asked for")
+		return false, false, nil
+	})
+	if err != nil {
+		t.Fatalf("Error creating callback: %s", err)
+	}
+	if err := reread("example.com:22", remote, key); err != nil {
+		t.Errorf("Expected persisted key to be trusted, got %s", err)
+	}
+
+	err = reread("example.com:22", remote, newTestPublicKey(t))
+	if handshakeError("example.com:22", err) == err {
+		t.Errorf("Expected mismatching key to be rejected as mismatch, got %v", err)
+	}
+}
+
+func TestTrustOnFirstUseRejected(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	callback, err := TrustOnFirstUse(path, func(string, net.Addr, ssh.PublicKey) (bool, bool, error) {
+		return false, false, nil
+	})
+	if err != nil {
+		t.Fatalf("Error creating callback: %s", err)
+	}
+	remote := &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 22}
+	if err := callback("example.com:22", remote, newTestPublicKey(t)); err == nil {
+		t.Errorf("Expected rejected key to fail")
+	}
+}
+
+func TestPromptHostKey(t *testing.T) {
+	var out bytes.Buffer
+	prompt := PromptHostKey(strings.NewReader("maybe\nyes\n"), &out)
+
+	trust, persist, err := prompt("example.com:22", nil, newTestPublicKey(t))
+	if err != nil || !trust || !persist {
+		t.Errorf("Expected key to be trusted and persisted, got %v %v %v", trust, persist, err)
+	}
+	if !strings.Contains(out.String(), "Are you sure you want to continue connecting (yes/no)? ") ||
+		!strings.Contains(out.String(), "Please type 'yes' or 'no': ") {
+		t.Errorf("Unexpected prompt: %q", out.String())
+	}
+
+	if trust, _, err := prompt("example.com:22", nil, newTestPublicKey(t)); trust || err == nil {
+		t.Errorf("Expected error at end of input, got %v %v", trust, err)
+	}
+}
@@ -0,0 +1,119 @@
+package easyssh
+
+import (
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// DefaultIdentityFiles are the private keys tried if neither Key nor KeyData
+// is set, in the same order as OpenSSH does.
+var DefaultIdentityFiles = []string{
+	"~/.ssh/id_ed25519",
+	"~/.ssh/id_ecdsa",
+	"~/.ssh/id_rsa",
+	"~/.ssh/id_dsa",
+}
+
+// keySigners returns signers for all configured private keys in the order they
+// are tried: Key or KeyData, Keys, KeysData and Signers. Keys with a
+// certificate are offered with the certificate first. If no key is configured
+// at all, the IdentityFiles are used instead.
+func (ssh_conf *MakeConfig) keySigners() ([]ssh.Signer, error) {
+	var signers []ssh.Signer
+
+	addKey := func(data []byte, keyFile string, primary bool) error {
+		signer, err := parseKey(data, ssh_conf.KeyPassphrase)
+		if err != nil {
+			return err
+		}
+
+		cert, err := ssh_conf.certificateFor(keyFile, primary)
+		if err != nil {
+			return err
+		}
+		if cert == nil {
+			signers = append(signers, signer)
+			return nil
+		}
+
+		certSigner, err := certSigner(cert, signer)
+		if err != nil {
+			return err
+		}
+		signers = append(signers, certSigner, signer)
+		return nil
+	}
+
+	readKey := func(keyFile string, primary bool) error {
+		if ssh_conf.InMemory {
+			return errInMemoryKeyFile
+		}
+		data, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return err
+		}
+		return addKey(data, keyFile, primary)
+	}
+
+	var err error
+	if len(ssh_conf.KeyData) > 0 {
+		err = addKey(ssh_conf.KeyData, "", true)
+	} else if ssh_conf.Key != "" {
+		err = readKey(ssh_conf.Key, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, keyFile := range ssh_conf.Keys {
+		if err := readKey(keyFile, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, data := range ssh_conf.KeysData {
+		if err := addKey(data, "", false); err != nil {
+			return nil, err
+		}
+	}
+	signers = append(signers, ssh_conf.Signers...)
+
+	noKeys := len(ssh_conf.KeyData) == 0 && ssh_conf.Key == "" && len(ssh_conf.Keys) == 0 && len(ssh_conf.KeysData) == 0 &&
+		len(ssh_conf.Signers) == 0
+	if noKeys && !ssh_conf.InMemory {
+		signers = ssh_conf.defaultIdentities()
+	}
+
+	return signers, nil
+}
+
+// returns signers for all identity files which exist and can be used without
+// asking for a passphrase
+func (ssh_conf *MakeConfig) defaultIdentities() []ssh.Signer {
+	files := ssh_conf.IdentityFiles
+	if files == nil {
+		files = DefaultIdentityFiles
+	}
+
+	var signers []ssh.Signer
+	for _, file := range files {
+		file, err := expandHome(file)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+
+		signer, err := getKeyFile(file, ssh_conf.KeyPassphrase)
+		if err != nil {
+			ssh_conf.logf(LogDebug1, "Skipping identity file %s: %s", file, err)
+			continue
+		}
+		ssh_conf.logf(LogDebug1, "Using identity file %s", file)
+		signers = append(signers, signer)
+	}
+
+	return signers
+}
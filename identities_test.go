@@ -0,0 +1,127 @@
+package easyssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
+)
+
+func TestDefaultIdentities(t *testing.T) {
+	dir, err := ioutil.TempDir("", "easyssh")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("Error marshaling key: %s", err)
+	}
+
+	valid := filepath.Join(dir, "id_ed25519")
+	invalid := filepath.Join(dir, "id_rsa")
+	if err := ioutil.WriteFile(valid, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("Error writing key: %s", err)
+	}
+	if err := ioutil.WriteFile(invalid, []byte("garbage"), 0600); err != nil {
+		t.Fatalf("Error writing key: %s", err)
+	}
+
+	cfg := &MakeConfig{IdentityFiles: []string{filepath.Join(dir, "missing"), invalid, valid}}
+	signers := cfg.defaultIdentities()
+	if len(signers) != 1 {
+		t.Fatalf("Expected 1 usable identity, got %d", len(signers))
+	}
+	if signers[0].PublicKey().Type() != ssh.KeyAlgoED25519 {
+		t.Errorf("Expected ed25519 identity, got %s", signers[0].PublicKey().Type())
+	}
+}
+
+func TestKeySignersOrder(t *testing.T) {
+	var keys [][]byte
+	var expected []string
+	for i := 0; i < 3; i++ {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("Error generating key: %s", err)
+		}
+		block, err := ssh.MarshalPrivateKey(priv, "")
+		if err != nil {
+			t.Fatalf("Error marshaling key: %s", err)
+		}
+		keys = append(keys, pem.EncodeToMemory(block))
+		signer, _ := ssh.NewSignerFromKey(priv)
+		expected = append(expected, ssh.FingerprintSHA256(signer.PublicKey()))
+	}
+
+	cfg := &MakeConfig{KeyData: keys[0], KeysData: keys[1:], IdentityFiles: []string{}}
+	signers, err := cfg.keySigners()
+	if err != nil {
+		t.Fatalf("Error loading keys: %s", err)
+	}
+	if len(signers) != len(expected) {
+		t.Fatalf("Expected %d signers, got %d", len(expected), len(signers))
+	}
+	for i, signer := range signers {
+		if fp := ssh.FingerprintSHA256(signer.PublicKey()); fp != expected[i] {
+			t.Errorf("Expected key %d to be %s, got %s", i, expected[i], fp)
+		}
+	}
+}
+
+func TestParsingMultipleIdentityFiles(t *testing.T) {
+	cfg := `
+Host multi
+	IdentityFile /keys/first
+	IdentityFile /keys/second
+	IdentityFile /keys/third
+`
+	result, err := parseClientConfig(strings.NewReader(cfg), "multi")
+	if err != nil {
+		t.Fatalf("Error parsing config: %s", err)
+	}
+	if result.Key != "/keys/first" {
+		t.Errorf("Expected first identity file as Key, got '%s'", result.Key)
+	}
+	if len(result.Keys) != 2 || result.Keys[0] != "/keys/second" || result.Keys[1] != "/keys/third" {
+		t.Errorf("Expected remaining identity files in Keys, got %v", result.Keys)
+	}
+}
+
+func TestCustomSigners(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("Error creating signer: %s", err)
+	}
+
+	srv, err := testserver.New("test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	srv.Authorize(signer.PublicKey())
+	srv.Handle("whoami", testserver.Reply("test\n", 0))
+
+	// InMemory makes sure that neither key files nor the agent are used
+	cfg := &MakeConfig{User: "test", Server: srv.Host(), Port: srv.Port(), InMemory: true,
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey()), Signers: []ssh.Signer{signer}}
+	if out, err := cfg.Run("whoami"); err != nil || out != "test\n" {
+		t.Errorf("Expected to log in using the signer, got %q (%v)", out, err)
+	}
+}
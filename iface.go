@@ -0,0 +1,12 @@
+package easyssh
+
+import "gopkg.in/hypersleep/easyssh.v0/iface"
+
+// make sure MakeConfig and Client implement the interfaces of package iface
+var (
+	_ iface.Runner          = (*MakeConfig)(nil)
+	_ iface.FileTransferrer = (*MakeConfig)(nil)
+	_ iface.Executor        = (*MakeConfig)(nil)
+	_ iface.Connector       = (*Client)(nil)
+	_ iface.Forwarder       = (*Client)(nil)
+)
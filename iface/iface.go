@@ -0,0 +1,66 @@
+// Package iface contains the interfaces implemented by easyssh. Code depending
+// on these instead of *easyssh.MakeConfig can easily swap implementations, for
+// example to use a mock in tests.
+package iface
+
+import (
+	"io"
+	"net"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Connector is an established connection to a remote machine, on which
+// sessions for features not covered by the other interfaces can be opened.
+type Connector interface {
+	// NewSession opens a new session on the connection.
+	NewSession() (*ssh.Session, error)
+	// Close closes the connection.
+	Close() error
+}
+
+// Forwarder tunnels network connections through an established connection to
+// a remote machine.
+type Forwarder interface {
+	// Dial connects to addr from the remote machine.
+	Dial(network, addr string) (net.Conn, error)
+	// Listen asks the remote machine to listen on addr and returns a listener
+	// for the connections made to it.
+	Listen(network, addr string) (net.Listener, error)
+}
+
+// Runner runs commands on a remote machine.
+type Runner interface {
+	// Run runs command and returns its output as a string.
+	Run(command string) (string, error)
+	// RunWithInput runs command with stdin as its standard input and returns
+	// its output as a string.
+	RunWithInput(command string, stdin io.Reader) (string, error)
+	// RunTo runs command, copies its stdout and stderr to the given writers
+	// and returns its exit status.
+	RunTo(command string, stdout, stderr io.Writer) (int, error)
+	// Stream runs command and returns a channel receiving its output line by
+	// line and another one receiving true once the command is done.
+	Stream(command string) (chan string, chan bool, error)
+	// StreamWithInput works like Stream, but with stdin as the standard input
+	// of the command.
+	StreamWithInput(command string, stdin io.Reader) (chan string, chan bool, error)
+}
+
+// FileTransferrer copies files to and from a remote machine.
+type FileTransferrer interface {
+	// Upload copies the local file sourceFile to targetFile on the remote
+	// machine.
+	Upload(sourceFile, targetFile string) error
+	// Download copies the remote file sourceFile to targetFile on the local
+	// machine.
+	Download(sourceFile, targetFile string) error
+}
+
+// Executor runs commands on and copies files to and from a remote machine. It
+// is implemented by *easyssh.MakeConfig and by the mock in package iface/mock
+// for unit tests which should not need an SSH server.
+type Executor interface {
+	Runner
+	FileTransferrer
+}
@@ -0,0 +1,169 @@
+// Package mock provides an in-memory implementation of iface.Executor for unit
+// tests of code using easyssh:
+//
+//	exec := mock.NewExecutor()
+//	exec.On("uptime", mock.Result{Output: "up 3 days\n"})
+//	exec.Files["/etc/hostname"] = []byte("web1\n")
+//	deploy(exec) // takes an iface.Executor
+//	// check exec.Calls() and exec.Files
+package mock
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"gopkg.in/hypersleep/easyssh.v0/iface"
+)
+
+// Result is the canned outcome of a command.
+type Result struct {
+	Output     string
+	Stderr     string
+	ExitStatus int
+	Err        error
+}
+
+// Executor records the commands run and answers them with the results
+// registered using On. Files holds the content of the remote files, which
+// Upload writes to and Download reads from.
+type Executor struct {
+	Files map[string][]byte
+
+	mu      sync.Mutex
+	results map[string]Result
+	calls   []string
+	inputs  map[string][]byte
+}
+
+var _ iface.Executor = (*Executor)(nil)
+
+// NewExecutor returns an Executor without any commands or files.
+func NewExecutor() *Executor {
+	return &Executor{Files: map[string][]byte{}, results: map[string]Result{}, inputs: map[string][]byte{}}
+}
+
+// On registers result as the outcome of command.
+func (e *Executor) On(command string, result Result) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.results[command] = result
+}
+
+// Calls returns the commands run so far in order.
+func (e *Executor) Calls() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.calls...)
+}
+
+// Input returns the data passed as stdin to the last run of command.
+func (e *Executor) Input(command string) []byte {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.inputs[command]
+}
+
+// call records command and returns its result. Commands without result fail.
+func (e *Executor) call(command string, stdin io.Reader) Result {
+	var input []byte
+	if stdin != nil {
+		input, _ = ioutil.ReadAll(stdin)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.calls = append(e.calls, command)
+	if stdin != nil {
+		e.inputs[command] = input
+	}
+	result, ok := e.results[command]
+	if !ok {
+		return Result{ExitStatus: -1, Err: fmt.Errorf("Unexpected command: '%s'", command)}
+	}
+	return result
+}
+
+// Run implements iface.Runner.
+func (e *Executor) Run(command string) (string, error) {
+	return e.RunWithInput(command, nil)
+}
+
+// RunWithInput implements iface.Runner.
+func (e *Executor) RunWithInput(command string, stdin io.Reader) (string, error) {
+	result := e.call(command, stdin)
+	return result.Output + result.Stderr, result.Err
+}
+
+// RunTo implements iface.Runner.
+func (e *Executor) RunTo(command string, stdout, stderr io.Writer) (int, error) {
+	result := e.call(command, nil)
+	if stdout != nil {
+		io.WriteString(stdout, result.Output)
+	}
+	if stderr != nil {
+		io.WriteString(stderr, result.Stderr)
+	}
+	return result.ExitStatus, result.Err
+}
+
+// Stream implements iface.Runner.
+func (e *Executor) Stream(command string) (chan string, chan bool, error) {
+	return e.StreamWithInput(command, nil)
+}
+
+// StreamWithInput implements iface.Runner.
+func (e *Executor) StreamWithInput(command string, stdin io.Reader) (chan string, chan bool, error) {
+	result := e.call(command, stdin)
+	if result.Err != nil {
+		return nil, nil, result.Err
+	}
+
+	output := strings.TrimSuffix(result.Output+result.Stderr, "\n")
+	lines := strings.Split(output, "\n")
+	if output == "" {
+		lines = nil
+	}
+
+	outputChan := make(chan string)
+	done := make(chan bool)
+	go func() {
+		defer close(outputChan)
+		defer close(done)
+		for _, line := range lines {
+			outputChan <- line
+		}
+		done <- true
+	}()
+	return outputChan, done, nil
+}
+
+// Upload implements iface.FileTransferrer by copying the local file
+// sourceFile to Files.
+func (e *Executor) Upload(sourceFile, targetFile string) error {
+	data, err := ioutil.ReadFile(sourceFile)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.Files[targetFile] = data
+	return nil
+}
+
+// Download implements iface.FileTransferrer by writing the content of
+// sourceFile in Files to the local file targetFile.
+func (e *Executor) Download(sourceFile, targetFile string) error {
+	e.mu.Lock()
+	data, ok := e.Files[sourceFile]
+	e.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("open %s: file does not exist", sourceFile)
+	}
+	return ioutil.WriteFile(targetFile, data, 0644)
+}
@@ -0,0 +1,75 @@
+package mock
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExecutor(t *testing.T) {
+	e := NewExecutor()
+	e.On("uptime", Result{Output: "up 3 days\n"})
+	e.On("false", Result{ExitStatus: 1})
+
+	if out, err := e.Run("uptime"); err != nil || out != "up 3 days\n" {
+		t.Errorf("Expected canned output, got %q (%v)", out, err)
+	}
+	if status, err := e.RunTo("false", nil, nil); err != nil || status != 1 {
+		t.Errorf("Expected exit status 1, got %d (%v)", status, err)
+	}
+	if _, err := e.RunWithInput("cat", strings.NewReader("data")); err == nil {
+		t.Errorf("Expected unexpected command to fail")
+	}
+	if string(e.Input("cat")) != "data" {
+		t.Errorf("Expected stdin to be recorded, got %q", e.Input("cat"))
+	}
+
+	output, done, err := e.Stream("uptime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for running := true; running; {
+		select {
+		case line := <-output:
+			lines = append(lines, line)
+		case <-done:
+			running = false
+		}
+	}
+	if !reflect.DeepEqual(lines, []string{"up 3 days"}) {
+		t.Errorf("Expected streamed lines, got %q", lines)
+	}
+
+	expected := []string{"uptime", "false", "cat", "uptime"}
+	if !reflect.DeepEqual(e.Calls(), expected) {
+		t.Errorf("Expected calls %q, got %q", expected, e.Calls())
+	}
+}
+
+func TestExecutorFiles(t *testing.T) {
+	dir := t.TempDir()
+	local := filepath.Join(dir, "app.conf")
+	ioutil.WriteFile(local, []byte("port=80\n"), 0644)
+
+	e := NewExecutor()
+	if err := e.Upload(local, "/etc/app.conf"); err != nil {
+		t.Fatal(err)
+	}
+	if string(e.Files["/etc/app.conf"]) != "port=80\n" {
+		t.Errorf("Expected uploaded file, got %q", e.Files["/etc/app.conf"])
+	}
+
+	downloaded := filepath.Join(dir, "copy.conf")
+	if err := e.Download("/etc/app.conf", downloaded); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(downloaded); string(data) != "port=80\n" {
+		t.Errorf("Expected downloaded file, got %q", data)
+	}
+	if err := e.Download("/missing", downloaded); err == nil {
+		t.Errorf("Expected error for missing remote file")
+	}
+}
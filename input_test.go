@@ -0,0 +1,57 @@
+package easyssh
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRunWithInput(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	// returns only once stdin reached EOF
+	srv.Handle("cat", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.Copy(stdout, stdin)
+		return 0
+	})
+
+	out, err := cfg.RunWithInput("cat", strings.NewReader("line 1\nline 2\n"))
+	if err != nil || out != "line 1\nline 2\n" {
+		t.Errorf("Expected input to be echoed, got %q (%v)", out, err)
+	}
+	for _, req := range srv.Requests() {
+		if req == "pty-req" {
+			t.Errorf("Expected no pty to be requested, got %v", srv.Requests())
+		}
+	}
+}
+
+func TestStreamWithInput(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.Handle("cat", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.Copy(stdout, stdin)
+		return 0
+	})
+
+	output, done, err := cfg.StreamWithInput("cat", strings.NewReader("a\nb\n"))
+	if err != nil {
+		t.Fatalf("Error streaming: %s", err)
+	}
+	var lines []string
+	for finished := false; !finished; {
+		select {
+		case line := <-output:
+			lines = append(lines, line)
+		case <-done:
+			finished = true
+		}
+	}
+
+	if len(lines) != 2 || lines[0] != "a" || lines[1] != "b" {
+		t.Errorf("Expected input to be echoed, got %q", lines)
+	}
+	if requests := srv.Requests(); len(requests) != 1 || requests[0] != "exec" {
+		t.Errorf("Expected only an exec request, got %v", requests)
+	}
+}
@@ -0,0 +1,224 @@
+package easyssh
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AllHosts is the name of the implicit group of an Inventory containing all
+// of its hosts.
+const AllHosts = "all"
+
+// Inventory is a set of named hosts with variables of their own, which are
+// organized in named groups, similar to an Ansible inventory.
+type Inventory struct {
+	// Concurrency limits the number of hosts worked on at the same time, see
+	// Group.
+	Concurrency int
+
+	hosts  map[string]*MakeConfig
+	vars   map[string]map[string]string
+	groups map[string][]string
+}
+
+// InventoryFile is the document read by LoadInventoryJSON and
+// LoadInventoryYAML. Hosts are described like in a FileConfig, with the name
+// of the host used if host is not given. Hosts can be added to groups either
+// by listing the groups for the host or the hosts for the group:
+//
+//	hosts:
+//	  web1.example.com:
+//	    user: deploy
+//	    vars: {role: frontend}
+//	    groups: [web]
+//	  db1:
+//	    host: 10.0.0.5
+//	groups:
+//	  databases: [db1]
+type InventoryFile struct {
+	Hosts  map[string]InventoryHost `json:"hosts" yaml:"hosts"`
+	Groups map[string][]string      `json:"groups" yaml:"groups"`
+}
+
+// InventoryHost is a host of an InventoryFile.
+type InventoryHost struct {
+	FileConfig `yaml:",inline"`
+	Vars       map[string]string `json:"vars" yaml:"vars"`
+	Groups     []string          `json:"groups" yaml:"groups"`
+}
+
+// NewInventory returns an empty Inventory.
+func NewInventory() *Inventory {
+	return &Inventory{
+		hosts:  map[string]*MakeConfig{},
+		vars:   map[string]map[string]string{},
+		groups: map[string][]string{},
+	}
+}
+
+// Add adds host to the inventory as name, with the variables vars, and makes
+// it a member of groups. An existing host of the same name is replaced.
+func (inv *Inventory) Add(name string, host *MakeConfig, vars map[string]string, groups ...string) {
+	inv.hosts[name] = host
+	inv.vars[name] = vars
+	for _, group := range groups {
+		inv.addToGroup(group, name)
+	}
+}
+
+func (inv *Inventory) addToGroup(group, name string) {
+	for _, member := range inv.groups[group] {
+		if member == name {
+			return
+		}
+	}
+	inv.groups[group] = append(inv.groups[group], name)
+}
+
+// Host returns the host called name, or nil if there is none.
+func (inv *Inventory) Host(name string) *MakeConfig {
+	return inv.hosts[name]
+}
+
+// Vars returns the variables of the host called name.
+func (inv *Inventory) Vars(name string) map[string]string {
+	return inv.vars[name]
+}
+
+// Names returns the sorted names of all hosts.
+func (inv *Inventory) Names() []string {
+	names := make([]string, 0, len(inv.hosts))
+	for name := range inv.hosts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Group returns a Group of the hosts matching any of targets, which are names
+// of groups or hosts, or AllHosts. The hosts are ordered by name.
+func (inv *Inventory) Group(targets ...string) (*Group, error) {
+	selected := map[string]bool{}
+	for _, target := range targets {
+		switch {
+		case target == AllHosts:
+			for name := range inv.hosts {
+				selected[name] = true
+			}
+		case inv.groups[target] != nil:
+			for _, name := range inv.groups[target] {
+				selected[name] = true
+			}
+		case inv.hosts[target] != nil:
+			selected[target] = true
+		default:
+			return nil, fmt.Errorf("Unknown group or host '%s'", target)
+		}
+	}
+
+	g := &Group{Concurrency: inv.Concurrency}
+	for _, name := range inv.Names() {
+		if selected[name] {
+			g.Hosts = append(g.Hosts, inv.hosts[name])
+		}
+	}
+	return g, nil
+}
+
+// Run runs command on all hosts matching target (see Group) in parallel.
+func (inv *Inventory) Run(target, command string) (Results, error) {
+	g, err := inv.Group(target)
+	if err != nil {
+		return nil, err
+	}
+	return g.Run(command), nil
+}
+
+// Upload uploads the local file sourceFile to targetFile on all hosts matching
+// target (see Group) in parallel.
+func (inv *Inventory) Upload(target, sourceFile, targetFile string) (Results, error) {
+	g, err := inv.Group(target)
+	if err != nil {
+		return nil, err
+	}
+	return g.Upload(sourceFile, targetFile), nil
+}
+
+// Inventory converts the document to an Inventory.
+func (f *InventoryFile) Inventory() (*Inventory, error) {
+	inv := NewInventory()
+
+	for name, h := range f.Hosts {
+		c := h.FileConfig
+		if c.Host == "" {
+			c.Host = name
+		}
+		host, err := c.MakeConfig()
+		if err != nil {
+			return nil, fmt.Errorf("Error in host '%s': %s", name, err)
+		}
+		inv.Add(name, host, h.Vars, h.Groups...)
+	}
+
+	for group, names := range f.Groups {
+		for _, name := range names {
+			if inv.hosts[name] == nil {
+				return nil, fmt.Errorf("Unknown host '%s' in group '%s'", name, group)
+			}
+			inv.addToGroup(group, name)
+		}
+	}
+	for _, names := range inv.groups {
+		sort.Strings(names)
+	}
+
+	return inv, nil
+}
+
+// LoadInventoryJSON returns the Inventory described by a JSON document (see
+// InventoryFile).
+func LoadInventoryJSON(data []byte) (*Inventory, error) {
+	var f InventoryFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&f); err != nil {
+		return nil, fmt.Errorf("Error parsing JSON inventory: %s", err)
+	}
+	return f.Inventory()
+}
+
+// LoadInventoryYAML returns the Inventory described by a YAML document (see
+// InventoryFile).
+func LoadInventoryYAML(data []byte) (*Inventory, error) {
+	var f InventoryFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&f); err != nil {
+		return nil, fmt.Errorf("Error parsing YAML inventory: %s", err)
+	}
+	return f.Inventory()
+}
+
+// LoadInventoryFile reads the Inventory from a JSON or YAML file, depending on
+// its extension (.json, .yaml or .yml).
+func LoadInventoryFile(filename string) (*Inventory, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return LoadInventoryJSON(data)
+	case ".yaml", ".yml":
+		return LoadInventoryYAML(data)
+	}
+	return nil, fmt.Errorf("Unknown inventory file type: '%s'", filename)
+}
@@ -0,0 +1,72 @@
+package easyssh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadInventoryYAML(t *testing.T) {
+	inv, err := LoadInventoryYAML([]byte(`
+hosts:
+  web1.example.com:
+    user: deploy
+    vars: {role: frontend}
+    groups: [web]
+  web2.example.com:
+    user: deploy
+    groups: [web]
+  db1:
+    host: 10.0.0.5
+    port: 2222
+groups:
+  databases: [db1]
+  prod: [web2.example.com, db1]
+`))
+	if err != nil {
+		t.Fatalf("Error loading inventory: %s", err)
+	}
+
+	if host := inv.Host("db1"); host == nil || host.Server != "10.0.0.5" || host.Port != "2222" {
+		t.Errorf("Expected db1 to use its host and port, got %v", host)
+	}
+	if host := inv.Host("web1.example.com"); host == nil || host.Server != "web1.example.com" {
+		t.Errorf("Expected host name to be used as server, got %v", host)
+	}
+	if vars := inv.Vars("web1.example.com"); vars["role"] != "frontend" {
+		t.Errorf("Expected vars to be loaded, got %v", vars)
+	}
+
+	servers := func(targets ...string) []string {
+		g, err := inv.Group(targets...)
+		if err != nil {
+			t.Fatalf("Error selecting %v: %s", targets, err)
+		}
+		var result []string
+		for _, host := range g.Hosts {
+			result = append(result, host.Server)
+		}
+		return result
+	}
+
+	testCases := map[string][]string{
+		"web":  {"web1.example.com", "web2.example.com"},
+		"prod": {"10.0.0.5", "web2.example.com"},
+		"db1":  {"10.0.0.5"},
+		"all":  {"10.0.0.5", "web1.example.com", "web2.example.com"},
+	}
+	for target, expected := range testCases {
+		if result := servers(target); !reflect.DeepEqual(result, expected) {
+			t.Errorf("Expected %v for '%s', got %v", expected, target, result)
+		}
+	}
+	if result := servers("databases", "web"); len(result) != 3 {
+		t.Errorf("Expected union of groups, got %v", result)
+	}
+
+	if _, err := inv.Group("missing"); err == nil {
+		t.Errorf("Expected error for unknown group")
+	}
+	if _, err := LoadInventoryYAML([]byte("groups:\n  web: [nope]\n")); err == nil {
+		t.Errorf("Expected error for unknown host in group")
+	}
+}
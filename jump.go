@@ -0,0 +1,104 @@
+package easyssh
+
+import (
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// jumpCache holds the connection to a jump host while it is shared by several
+// hosts connecting through it.
+type jumpCache struct {
+	mu     sync.Mutex
+	client *ssh.Client
+	shared int
+}
+
+// returns the cache for connections to MakeConfig as a jump host
+func (ssh_conf *MakeConfig) jumps() *jumpCache {
+	lazyInit.Lock()
+	defer lazyInit.Unlock()
+
+	if ssh_conf.jump == nil {
+		ssh_conf.jump = &jumpCache{}
+	}
+	return ssh_conf.jump
+}
+
+// returns a client connected to MakeConfig to tunnel connections through. If a
+// shared connection is available it is used, otherwise a new one is dialed and
+// closed by the returned function.
+func (ssh_conf *MakeConfig) jumpClient() (*ssh.Client, func(), error) {
+	cache := ssh_conf.jumps()
+	cache.mu.Lock()
+	shared := cache.client
+	cache.mu.Unlock()
+
+	if shared != nil {
+		return shared, func() {}, nil
+	}
+
+	// dial without holding the lock, so connections through the same jump
+	// host are not made one after another
+	client, err := ssh_conf.dial(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client, func() { client.Close() }, nil
+}
+
+// shareJumpConnection dials MakeConfig once and lets all hosts using it as jump
+// host tunnel their connections through this single connection, until the
+// returned function is called.
+func (ssh_conf *MakeConfig) shareJumpConnection() (release func()) {
+	cache := ssh_conf.jumps()
+	cache.mu.Lock()
+	cache.shared++
+	connected := cache.client != nil
+	cache.mu.Unlock()
+
+	if !connected {
+		if client, err := ssh_conf.dial(nil); err == nil {
+			cache.mu.Lock()
+			if cache.client == nil {
+				cache.client = client
+			} else {
+				client.Close()
+			}
+			cache.mu.Unlock()
+		}
+	}
+
+	return func() {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+
+		cache.shared--
+		if cache.shared == 0 && cache.client != nil {
+			cache.client.Close()
+			cache.client = nil
+		}
+	}
+}
+
+// shareJumpHosts opens a single connection to every jump host used by members
+// of the group (identified by the same JumpHost pointer) which is shared by all
+// of them until the returned function is called.
+func (g *Group) shareJumpHosts() (release func()) {
+	var releases []func()
+	seen := map[*MakeConfig]bool{}
+
+	for _, host := range g.Hosts {
+		if jump := host.JumpHost; jump != nil && !seen[jump] {
+			seen[jump] = true
+			releases = append(releases, jump.shareJumpConnection())
+		}
+	}
+
+	return func() {
+		for _, release := range releases {
+			release()
+		}
+	}
+}
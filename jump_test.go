@@ -0,0 +1,51 @@
+package easyssh
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGroupSharesJumpHost(t *testing.T) {
+	bastion, jump := echoTestServer(t)
+	defer bastion.Close()
+	target, cfg := echoTestServer(t)
+	defer target.Close()
+
+	var hosts []*MakeConfig
+	for i := 0; i < 4; i++ {
+		host := *cfg
+		host.JumpHost = jump
+		hosts = append(hosts, &host)
+	}
+
+	for _, result := range NewGroup(hosts...).Run("uptime") {
+		if result.Err != nil || result.Stdout != "uptime\n" {
+			t.Errorf("Expected command to run through the jump host, got %q (%v)", result.Stdout, result.Err)
+		}
+	}
+	if n := bastion.Accepted(); n != 1 {
+		t.Errorf("Expected a single connection to the jump host, got %d", n)
+	}
+	if n := target.Accepted(); n != 4 {
+		t.Errorf("Expected 4 connections to the target, got %d", n)
+	}
+}
+
+func TestJumpHostErrorKind(t *testing.T) {
+	bastion, jump := echoTestServer(t)
+	defer bastion.Close()
+	target, cfg := echoTestServer(t)
+	defer target.Close()
+
+	jump.Password = "wrong"
+	cfg.JumpHost = jump
+	if _, err := cfg.Run("uptime"); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed of the jump host, got %v", err)
+	}
+
+	jump.Password = "secret"
+	bastion.Close()
+	if _, err := cfg.Run("uptime"); !errors.Is(err, ErrHostUnreachable) {
+		t.Errorf("Expected ErrHostUnreachable of the jump host, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package easyssh
+
+import (
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// LatencyResult holds the timings measured by Latency.
+// Dial is the time it took to open the TCP connection, Handshake the time
+// needed for the SSH handshake including authentication and Echo the round
+// trip time of running a trivial command on the established connection.
+type LatencyResult struct {
+	Dial      time.Duration
+	Handshake time.Duration
+	Echo      time.Duration
+}
+
+// Total returns the sum of all measured timings.
+func (l *LatencyResult) Total() time.Duration {
+	return l.Dial + l.Handshake + l.Echo
+}
+
+// Latency connects to the remote machine and measures how long the single
+// steps of establishing a connection and running a command take. This can be
+// used to pick the fastest of several hosts or to detect degraded network paths.
+func (ssh_conf *MakeConfig) Latency() (*LatencyResult, error) {
+	result := &LatencyResult{}
+	clock := ssh_conf.clock()
+
+	config, release, err := ssh_conf.clientConfig(nil)
+	defer release()
+	if err != nil {
+		return nil, err
+	}
+
+	start := clock.Now()
+	conn, addr, releaseConn, err := ssh_conf.dialConn()
+	if err != nil {
+		return nil, err
+	}
+	defer releaseConn()
+	result.Dial = clock.Now().Sub(start)
+
+	start = clock.Now()
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, handshakeError(addr, err)
+	}
+	client := ssh.NewClient(c, chans, reqs)
+	defer client.Close()
+	result.Handshake = clock.Now().Sub(start)
+
+	start = clock.Now()
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+	if err := ssh_conf.run(session, "echo"); err != nil {
+		return nil, err
+	}
+	result.Echo = clock.Now().Sub(start)
+
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package easyssh
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tickClock advances by a second every time it is read, so every measured
+// duration is positive no matter how fast the test runs.
+type tickClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *tickClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+func (c *tickClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// unreachableConfig returns a config pointing at a port nobody listens on.
+func unreachableConfig(t *testing.T) *MakeConfig {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	l.Close()
+	return &MakeConfig{User: "test", Password: "secret", Server: host, Port: port, InMemory: true}
+}
+
+func TestLatency(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	cfg.Clock = &tickClock{now: time.Unix(0, 0)}
+
+	result, err := cfg.Latency()
+	if err != nil {
+		t.Fatalf("Error measuring latency: %s", err)
+	}
+	if result.Dial <= 0 || result.Handshake <= 0 || result.Echo <= 0 {
+		t.Errorf("Expected all phases to be measured, got %+v", result)
+	}
+	if result.Total() != result.Dial+result.Handshake+result.Echo {
+		t.Errorf("Expected total to be the sum of all phases, got %s", result.Total())
+	}
+	if commands := srv.Commands(); len(commands) != 1 || commands[0] != "echo" {
+		t.Errorf("Expected a single echo command, got %v", commands)
+	}
+}
+
+func TestLatencyDialError(t *testing.T) {
+	result, err := unreachableConfig(t).Latency()
+	if err == nil {
+		t.Fatalf("Expected dial error, got %+v", result)
+	}
+	if result != nil {
+		t.Errorf("Expected no result on error, got %+v", result)
+	}
+}
+
+func TestGroupLatency(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	cfg.Clock = &tickClock{now: time.Unix(0, 0)}
+
+	results := NewGroup(cfg, unreachableConfig(t)).Latency()
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if ok := results[0]; ok.Err != nil || ok.Latency == nil || ok.Latency.Dial <= 0 || ok.Latency.Handshake <= 0 || ok.Latency.Echo <= 0 {
+		t.Errorf("Expected latency of first host, got %+v (%v)", ok.Latency, ok.Err)
+	}
+	if failed := results[1]; failed.Err == nil || failed.Latency != nil {
+		t.Errorf("Expected dial error for second host, got %+v", failed)
+	}
+}
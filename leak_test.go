@@ -0,0 +1,86 @@
+package easyssh
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
+)
+
+// waitForConnections fails t unless the connections to srv are all closed
+// within a few seconds.
+func waitForConnections(t *testing.T, srv *testserver.Server, after string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.Connections() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected all connections to be closed after %s, got %d open", after, srv.Connections())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestConnectionsClosed(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.HandleSubsystem("echo", func(name string, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.Copy(stdout, stdin)
+		return 0
+	})
+
+	if _, err := cfg.Run("echo run"); err != nil {
+		t.Fatalf("Error running command: %s", err)
+	}
+	waitForConnections(t, srv, "Run")
+
+	if _, err := cfg.RunTo("echo runto", ioutil.Discard, ioutil.Discard); err != nil {
+		t.Fatalf("Error running command: %s", err)
+	}
+	waitForConnections(t, srv, "RunTo")
+
+	output, done, err := cfg.Stream("echo stream")
+	if err != nil {
+		t.Fatalf("Error streaming command: %s", err)
+	}
+	for finished := false; !finished; {
+		select {
+		case <-output:
+		case <-done:
+			finished = true
+		}
+	}
+	waitForConnections(t, srv, "Stream")
+
+	raw, rawDone, err := cfg.StreamRaw("echo raw")
+	if err != nil {
+		t.Fatalf("Error streaming command: %s", err)
+	}
+	for finished := false; !finished; {
+		select {
+		case <-raw:
+		case <-rawDone:
+			finished = true
+		}
+	}
+	waitForConnections(t, srv, "StreamRaw")
+
+	cmd, err := cfg.StartCommand("echo start", ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		t.Fatalf("Error starting command: %s", err)
+	}
+	cmd.Wait()
+	waitForConnections(t, srv, "StartCommand")
+
+	subsystem, err := cfg.Subsystem("echo")
+	if err != nil {
+		t.Fatalf("Error starting subsystem: %s", err)
+	}
+	subsystem.Close()
+	waitForConnections(t, srv, "Subsystem")
+
+	if _, err := cfg.Subsystem("missing"); err == nil {
+		t.Fatalf("Expected starting a missing subsystem to fail")
+	}
+	waitForConnections(t, srv, "a failing Subsystem")
+}
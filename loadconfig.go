@@ -0,0 +1,209 @@
+package easyssh
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"gopkg.in/yaml.v3"
+)
+
+// FileConfig is the document read by LoadConfigJSON and LoadConfigYAML.
+// KnownHosts is the path of a known_hosts file and HostKeys a list of host keys
+// in authorized_keys format, which are used to check the host key. Without
+// them, HostKeyCallback needs to be set by the caller. RequestTimeout is given
+// as a duration like "30s". Port may be given as number or string.
+type FileConfig struct {
+	Host             string            `json:"host" yaml:"host"`
+	Fallbacks        []string          `json:"fallbacks" yaml:"fallbacks"`
+	Port             json.Number       `json:"port" yaml:"port"`
+	User             string            `json:"user" yaml:"user"`
+	Password         string            `json:"password" yaml:"password"`
+	Key              string            `json:"key" yaml:"key"`
+	Keys             []string          `json:"keys" yaml:"keys"`
+	KeyPassphrase    string            `json:"key_passphrase" yaml:"key_passphrase"`
+	Certificate      string            `json:"certificate" yaml:"certificate"`
+	KnownHosts       string            `json:"known_hosts" yaml:"known_hosts"`
+	HostKeys         []string          `json:"host_keys" yaml:"host_keys"`
+	JumpHost         *FileConfig       `json:"jump_host" yaml:"jump_host"`
+	Proxy            string            `json:"proxy" yaml:"proxy"`
+	ProxyCommand     string            `json:"proxy_command" yaml:"proxy_command"`
+	Dir              string            `json:"dir" yaml:"dir"`
+	Env              map[string]string `json:"env" yaml:"env"`
+	Labels           map[string]string `json:"labels" yaml:"labels"`
+	LogLevel         string            `json:"log_level" yaml:"log_level"`
+	RequestTimeout   string            `json:"request_timeout" yaml:"request_timeout"`
+	ForwardAgent     bool              `json:"forward_agent" yaml:"forward_agent"`
+	InlineEnv        bool              `json:"inline_env" yaml:"inline_env"`
+	LegacyAlgorithms bool              `json:"legacy_algorithms" yaml:"legacy_algorithms"`
+	ClientVersion    string            `json:"client_version" yaml:"client_version"`
+}
+
+// MakeConfig converts the document to a MakeConfig.
+func (c *FileConfig) MakeConfig() (*MakeConfig, error) {
+	if c.Host == "" {
+		return nil, fmt.Errorf("Missing host")
+	}
+
+	cfg := &MakeConfig{
+		Server:           c.Host,
+		Fallbacks:        c.Fallbacks,
+		Port:             string(c.Port),
+		User:             c.User,
+		Password:         c.Password,
+		KeyPassphrase:    c.KeyPassphrase,
+		Keys:             c.Keys,
+		Proxy:            c.Proxy,
+		ProxyCommand:     c.ProxyCommand,
+		Dir:              c.Dir,
+		Env:              c.Env,
+		Labels:           c.Labels,
+		ForwardAgent:     c.ForwardAgent,
+		InlineEnv:        c.InlineEnv,
+		LegacyAlgorithms: c.LegacyAlgorithms,
+		ClientVersion:    c.ClientVersion,
+	}
+	if cfg.Port == "" {
+		cfg.Port = "22"
+	}
+
+	var err error
+	if cfg.Key, err = expandHome(c.Key); err != nil {
+		return nil, err
+	}
+	if cfg.Certificate, err = expandHome(c.Certificate); err != nil {
+		return nil, err
+	}
+
+	if c.LogLevel != "" {
+		if cfg.LogLevel, err = ParseLogLevel(c.LogLevel); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.RequestTimeout != "" {
+		if cfg.RequestTimeout, err = time.ParseDuration(c.RequestTimeout); err != nil {
+			return nil, fmt.Errorf("Invalid request timeout '%s': %s", c.RequestTimeout, err)
+		}
+	}
+
+	if cfg.HostKeyCallback, err = c.hostKeyCallback(); err != nil {
+		return nil, err
+	}
+
+	if c.JumpHost != nil {
+		if cfg.JumpHost, err = c.JumpHost.MakeConfig(); err != nil {
+			return nil, fmt.Errorf("Error in jump host: %s", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// returns the HostKeyCallback for HostKeys or KnownHosts, or nil if neither
+// is given
+func (c *FileConfig) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if len(c.HostKeys) > 0 {
+		keys, err := ParseHostKeys([]byte(strings.Join(c.HostKeys, "\n")))
+		if err != nil {
+			return nil, err
+		}
+		return FixedHostKeys(keys...), nil
+	}
+
+	if c.KnownHosts != "" {
+		file, err := expandHome(c.KnownHosts)
+		if err != nil {
+			return nil, err
+		}
+		callback, err := knownhosts.New(file)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading known_hosts file '%s': %s", file, err)
+		}
+		return callback, nil
+	}
+
+	return nil, nil
+}
+
+// LoadConfigJSON returns the MakeConfig described by a JSON document (see
+// FileConfig for the fields).
+func LoadConfigJSON(data []byte) (*MakeConfig, error) {
+	var c FileConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("Error parsing JSON config: %s", err)
+	}
+	return c.MakeConfig()
+}
+
+// LoadConfigYAML returns the MakeConfig described by a YAML document (see
+// FileConfig for the fields).
+func LoadConfigYAML(data []byte) (*MakeConfig, error) {
+	var c FileConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("Error parsing YAML config: %s", err)
+	}
+	return c.MakeConfig()
+}
+
+// LoadConfigFile reads the MakeConfig from a JSON or YAML file, depending on
+// its extension (.json, .yaml or .yml).
+func LoadConfigFile(filename string) (*MakeConfig, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return LoadConfigJSON(data)
+	case ".yaml", ".yml":
+		return LoadConfigYAML(data)
+	}
+	return nil, fmt.Errorf("Unknown config file type: '%s'", filename)
+}
+
+// LoadConfigEnv returns the MakeConfig described by the environment variables
+// EASYSSH_HOST, EASYSSH_PORT, EASYSSH_USER, EASYSSH_PASSWORD, EASYSSH_KEY,
+// EASYSSH_KEY_PASSPHRASE, EASYSSH_CERTIFICATE, EASYSSH_KNOWN_HOSTS,
+// EASYSSH_PROXY, EASYSSH_PROXY_COMMAND, EASYSSH_DIR, EASYSSH_LOG_LEVEL,
+// EASYSSH_REQUEST_TIMEOUT and EASYSSH_FORWARD_AGENT (see FileConfig).
+func LoadConfigEnv() (*MakeConfig, error) {
+	c := FileConfig{
+		Host:           os.Getenv("EASYSSH_HOST"),
+		Port:           json.Number(os.Getenv("EASYSSH_PORT")),
+		User:           os.Getenv("EASYSSH_USER"),
+		Password:       os.Getenv("EASYSSH_PASSWORD"),
+		Key:            os.Getenv("EASYSSH_KEY"),
+		KeyPassphrase:  os.Getenv("EASYSSH_KEY_PASSPHRASE"),
+		Certificate:    os.Getenv("EASYSSH_CERTIFICATE"),
+		KnownHosts:     os.Getenv("EASYSSH_KNOWN_HOSTS"),
+		Proxy:          os.Getenv("EASYSSH_PROXY"),
+		ProxyCommand:   os.Getenv("EASYSSH_PROXY_COMMAND"),
+		Dir:            os.Getenv("EASYSSH_DIR"),
+		LogLevel:       os.Getenv("EASYSSH_LOG_LEVEL"),
+		RequestTimeout: os.Getenv("EASYSSH_REQUEST_TIMEOUT"),
+	}
+
+	if value := os.Getenv("EASYSSH_FORWARD_AGENT"); value != "" {
+		forward, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for EASYSSH_FORWARD_AGENT: '%s'", value)
+		}
+		c.ForwardAgent = forward
+	}
+
+	return c.MakeConfig()
+}
@@ -0,0 +1,86 @@
+package easyssh
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigJSON(t *testing.T) {
+	cfg, err := LoadConfigJSON([]byte(`{
+		"host": "example.com",
+		"port": 2222,
+		"user": "deploy",
+		"key": "/keys/deploy",
+		"request_timeout": "30s",
+		"labels": {"env": "prod"},
+		"jump_host": {"host": "bastion", "user": "jump"}
+	}`))
+	if err != nil {
+		t.Fatalf("Error loading config: %s", err)
+	}
+
+	if cfg.Server != "example.com" || cfg.Port != "2222" || cfg.User != "deploy" || cfg.Key != "/keys/deploy" {
+		t.Errorf("Unexpected config: %s", cfg.Redacted())
+	}
+	if cfg.RequestTimeout != 30*time.Second || cfg.Labels["env"] != "prod" {
+		t.Errorf("Expected timeout and labels to be set, got %v and %v", cfg.RequestTimeout, cfg.Labels)
+	}
+	if cfg.JumpHost == nil || cfg.JumpHost.String() != "jump@bastion:22" {
+		t.Errorf("Expected jump host, got %v", cfg.JumpHost)
+	}
+
+	if _, err := LoadConfigJSON([]byte(`{"host": "example.com", "hots": "typo"}`)); err == nil {
+		t.Errorf("Expected error for unknown field")
+	}
+	if _, err := LoadConfigJSON([]byte(`{"user": "deploy"}`)); err == nil {
+		t.Errorf("Expected error for missing host")
+	}
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	cfg, err := LoadConfigYAML([]byte(`
+host: example.com
+port: 22
+user: deploy
+host_keys:
+  - ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGgMHBBcXeDp1Ll/Y7G9ELSsBgIEaTMs1bpWp3SVtrNs
+env:
+  LANG: C
+`))
+	if err != nil {
+		t.Fatalf("Error loading config: %s", err)
+	}
+
+	if cfg.String() != "deploy@example.com:22" || cfg.Env["LANG"] != "C" {
+		t.Errorf("Unexpected config: %s %v", cfg, cfg.Env)
+	}
+	if cfg.HostKeyCallback == nil {
+		t.Errorf("Expected host keys to be checked")
+	}
+}
+
+func TestLoadConfigEnv(t *testing.T) {
+	for name, value := range map[string]string{
+		"EASYSSH_HOST":          "example.com",
+		"EASYSSH_USER":          "deploy",
+		"EASYSSH_PASSWORD":      "secret",
+		"EASYSSH_FORWARD_AGENT": "true",
+	} {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	cfg, err := LoadConfigEnv()
+	if err != nil {
+		t.Fatalf("Error loading config: %s", err)
+	}
+	if cfg.String() != "deploy@example.com:22" || cfg.Password != "secret" || !cfg.ForwardAgent {
+		t.Errorf("Unexpected config: %s", cfg.Redacted())
+	}
+
+	os.Setenv("EASYSSH_FORWARD_AGENT", "sure")
+	if _, err := LoadConfigEnv(); err == nil {
+		t.Errorf("Expected error for invalid boolean")
+	}
+}
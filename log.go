@@ -0,0 +1,106 @@
+package easyssh
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LogLevel is the verbosity of log messages, using the levels of ssh_config's
+// LogLevel. The zero value is LogInfo, which is OpenSSH's default as well.
+type LogLevel int
+
+const (
+	LogQuiet LogLevel = iota - 3
+	LogFatal
+	LogError
+	LogInfo
+	LogVerbose
+	LogDebug1
+	LogDebug2
+	LogDebug3
+
+	LogDebug = LogDebug1
+)
+
+var logLevelNames = map[LogLevel]string{
+	LogQuiet:   "QUIET",
+	LogFatal:   "FATAL",
+	LogError:   "ERROR",
+	LogInfo:    "INFO",
+	LogVerbose: "VERBOSE",
+	LogDebug1:  "DEBUG1",
+	LogDebug2:  "DEBUG2",
+	LogDebug3:  "DEBUG3",
+}
+
+func (l LogLevel) String() string {
+	if name, ok := logLevelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LogLevel(%d)", int(l))
+}
+
+// ParseLogLevel parses a log level as used by ssh_config (ex. "VERBOSE" or
+// "DEBUG2"). The names are case-insensitive and "DEBUG" is the same as
+// "DEBUG1".
+func ParseLogLevel(name string) (LogLevel, error) {
+	name = strings.ToUpper(name)
+	if name == "DEBUG" {
+		return LogDebug, nil
+	}
+
+	for level, levelName := range logLevelNames {
+		if name == levelName {
+			return level, nil
+		}
+	}
+
+	return LogInfo, fmt.Errorf("Unknown log level: '%s'", name)
+}
+
+// Logger receives the log messages of easyssh.
+type Logger interface {
+	Log(level LogLevel, msg string)
+}
+
+// LoggerFunc allows using an ordinary function as Logger.
+type LoggerFunc func(level LogLevel, msg string)
+
+func (f LoggerFunc) Log(level LogLevel, msg string) {
+	f(level, msg)
+}
+
+// logf passes a message to the Logger of MakeConfig if its level is enabled
+// by LogLevel.
+func (ssh_conf *MakeConfig) logf(level LogLevel, format string, args ...interface{}) {
+	if ssh_conf.Logger == nil || ssh_conf.LogLevel == LogQuiet || level > ssh_conf.LogLevel {
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	if l, ok := ssh_conf.Logger.(labelLogger); ok {
+		l.logLabels(level, msg, ssh_conf.Labels)
+		return
+	}
+	if labels := formatLabels(ssh_conf.Labels); labels != "" {
+		msg += " " + labels
+	}
+
+	ssh_conf.Logger.Log(level, msg)
+}
+
+// formatLabels returns labels as "[key=value ...]", sorted by key.
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+
+	return "[" + strings.Join(pairs, " ") + "]"
+}
@@ -0,0 +1,113 @@
+package easyssh
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	testCases := map[string]LogLevel{
+		"QUIET":   LogQuiet,
+		"fatal":   LogFatal,
+		"Error":   LogError,
+		"INFO":    LogInfo,
+		"VERBOSE": LogVerbose,
+		"DEBUG":   LogDebug1,
+		"DEBUG1":  LogDebug1,
+		"DEBUG2":  LogDebug2,
+		"debug3":  LogDebug3,
+	}
+
+	for input, expected := range testCases {
+		level, err := ParseLogLevel(input)
+		if err != nil {
+			t.Errorf("Error parsing log level '%s': %s", input, err)
+		}
+		if level != expected {
+			t.Errorf("Expected %s for '%s', got %s", expected, input, level)
+		}
+	}
+
+	if _, err := ParseLogLevel("LOUD"); err == nil {
+		t.Errorf("Expected error for unknown log level")
+	}
+}
+
+func TestParsingLogLevelFromClientConfig(t *testing.T) {
+	cfg := `
+Host quiet
+	LogLevel QUIET
+Host chatty
+	LogLevel DEBUG2
+`
+	for host, expected := range map[string]LogLevel{"quiet": LogQuiet, "chatty": LogDebug2} {
+		result, err := parseClientConfig(strings.NewReader(cfg), host)
+		if err != nil {
+			t.Fatalf("Error parsing config: %s", err)
+		}
+		if result.LogLevel != expected {
+			t.Errorf("Expected log level %s for '%s', got %s", expected, host, result.LogLevel)
+		}
+	}
+}
+
+func TestLogf(t *testing.T) {
+	var messages []string
+	cfg := &MakeConfig{
+		Logger: LoggerFunc(func(level LogLevel, msg string) {
+			messages = append(messages, level.String()+" "+msg)
+		}),
+	}
+
+	cfg.logf(LogError, "error %d", 1)
+	cfg.logf(LogDebug1, "debug %d", 1)
+	cfg.LogLevel = LogDebug1
+	cfg.logf(LogDebug1, "debug %d", 2)
+	cfg.LogLevel = LogQuiet
+	cfg.logf(LogFatal, "fatal %d", 1)
+
+	expected := "ERROR error 1, DEBUG1 debug 2"
+	if result := strings.Join(messages, ", "); result != expected {
+		t.Errorf("Expected messages '%s', got '%s'", expected, result)
+	}
+
+	messages = nil
+	cfg.LogLevel = LogInfo
+	cfg.Labels = map[string]string{"tenant": "acme", "env": "prod"}
+	cfg.logf(LogInfo, "labeled")
+	if expected := "INFO labeled [env=prod tenant=acme]"; len(messages) != 1 || messages[0] != expected {
+		t.Errorf("Expected messages '%s', got %v", expected, messages)
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug - 3}))
+
+	cfg := &MakeConfig{Logger: SlogLogger(logger), LogLevel: LogDebug3, Labels: map[string]string{"env": "prod"}}
+	cfg.logf(LogError, "Error connecting")
+	cfg.logf(LogDebug2, "Opened session")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log records, got %q", buf.String())
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Error parsing record: %s", err)
+	}
+	if record["level"] != "ERROR" || record["msg"] != "Error connecting" || record["env"] != "prod" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("Error parsing record: %s", err)
+	}
+	if record["level"] != "DEBUG-2" {
+		t.Errorf("Expected DEBUG-2 level for LogDebug2, got %v", record["level"])
+	}
+}
@@ -0,0 +1,50 @@
+package easyssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var errInMemoryKeyFile = errors.New("Reading key files is disabled in InMemory mode, use KeyData instead")
+
+// FixedHostKeys returns a HostKeyCallback accepting only the given host keys.
+// In contrast to a known_hosts file, the keys are not tied to host names, so
+// this is meant to be used for MakeConfigs of a single host or a set of hosts
+// sharing their keys. Other keys are rejected with an error matching
+// ErrHostKeyMismatch.
+func FixedHostKeys(keys ...ssh.PublicKey) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		for _, known := range keys {
+			if known.Type() == key.Type() && bytes.Equal(known.Marshal(), key.Marshal()) {
+				return nil
+			}
+		}
+		err := fmt.Errorf("Host key %s is not trusted", ssh.FingerprintSHA256(key))
+		return &ConnectError{Kind: ErrHostKeyMismatch, Host: hostname, Err: err}
+	}
+}
+
+// ParseHostKeys parses host keys in authorized_keys or known_hosts format
+// (ex. "ssh-ed25519 AAAA..."), one per line, for use with FixedHostKeys.
+func ParseHostKeys(data []byte) ([]ssh.PublicKey, error) {
+	var keys []ssh.PublicKey
+
+	for {
+		key, _, _, rest, err := ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			break
+		}
+		keys = append(keys, key)
+		data = rest
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("No host keys found")
+	}
+
+	return keys, nil
+}
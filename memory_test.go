@@ -0,0 +1,65 @@
+package easyssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestPublicKey(t *testing.T) ssh.PublicKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("Error converting key: %s", err)
+	}
+	return key
+}
+
+func TestFixedHostKeys(t *testing.T) {
+	trusted, other := newTestPublicKey(t), newTestPublicKey(t)
+
+	data := "# trusted hosts\n" + string(ssh.MarshalAuthorizedKey(trusted)) +
+		"example.com " + string(ssh.MarshalAuthorizedKey(other))
+	keys, err := ParseHostKeys([]byte(data))
+	if err != nil {
+		t.Fatalf("Error parsing host keys: %s", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 host keys, got %d", len(keys))
+	}
+
+	callback := FixedHostKeys(keys[0])
+	if err := callback("example.com:22", nil, trusted); err != nil {
+		t.Errorf("Expected trusted key to be accepted, got %s", err)
+	}
+	if err := callback("example.com:22", nil, other); !errors.Is(err, ErrHostKeyMismatch) {
+		t.Errorf("Expected other key to be rejected with ErrHostKeyMismatch, got %v", err)
+	}
+
+	if _, err := ParseHostKeys([]byte("# nothing here\n")); err == nil {
+		t.Errorf("Expected error for data without host keys")
+	}
+}
+
+func TestFixedHostKeysMismatch(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+
+	cfg.HostKeyCallback = FixedHostKeys(newTestPublicKey(t))
+	if _, err := cfg.Run("true"); !errors.Is(err, ErrHostKeyMismatch) {
+		t.Errorf("Expected ErrHostKeyMismatch, got %v", err)
+	}
+}
+
+func TestInMemoryRejectsKeyFiles(t *testing.T) {
+	cfg := &MakeConfig{Key: "/home/john/.ssh/id_rsa", InMemory: true}
+	if _, _, err := cfg.clientConfig(nil); err != errInMemoryKeyFile {
+		t.Errorf("Expected key file to be rejected in InMemory mode, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package easyssh
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Metrics receives events about the connections, commands and transfers of a
+// MakeConfig, for monitoring services built on easyssh (see the promssh
+// package for Prometheus). The methods may be called concurrently.
+type Metrics interface {
+	// ConnectionOpened is called for every connection made, with the
+	// authentication method which succeeded ("password",
+	// "keyboard-interactive", "publickey" or "agent") and the time taken.
+	ConnectionOpened(host *MakeConfig, authMethod string, duration time.Duration)
+	// ConnectionFailed is called if connecting failed.
+	ConnectionFailed(host *MakeConfig, err error)
+	// CommandStarted is called once a command was started in a session.
+	CommandStarted(host *MakeConfig)
+	// CommandFinished is called once a started command exited. The exit
+	// status is -1 if it did not exit normally.
+	CommandFinished(host *MakeConfig, duration time.Duration, exitStatus int)
+	// BytesTransferred is called after n bytes of files were transferred in
+	// direction "upload" or "download".
+	BytesTransferred(host *MakeConfig, direction string, n int64)
+}
+
+// runningCommands holds the commands running while Metrics or Tracer is set,
+// by their session.
+var runningCommands sync.Map
+
+type runningCommand struct {
+	started time.Time
+	span    Span
+}
+
+func (ssh_conf *MakeConfig) connectionOpened(authMethod string, started time.Time) {
+	if ssh_conf.Metrics != nil {
+		ssh_conf.Metrics.ConnectionOpened(ssh_conf, authMethod, ssh_conf.clock().Now().Sub(started))
+	}
+}
+
+func (ssh_conf *MakeConfig) connectionFailed(err error) {
+	if ssh_conf.Metrics != nil {
+		ssh_conf.Metrics.ConnectionFailed(ssh_conf, err)
+	}
+}
+
+func (ssh_conf *MakeConfig) commandStarted(session *ssh.Session, command string) {
+	if ssh_conf.Metrics == nil && ssh_conf.Tracer == nil {
+		return
+	}
+
+	span := ssh_conf.startSpan("ssh.command")
+	span.SetAttribute("ssh.command", command)
+	runningCommands.Store(session, runningCommand{started: ssh_conf.clock().Now(), span: span})
+	if ssh_conf.Metrics != nil {
+		ssh_conf.Metrics.CommandStarted(ssh_conf)
+	}
+}
+
+// wait waits for the command started on session to exit, like session.Wait.
+func (ssh_conf *MakeConfig) wait(session *ssh.Session) error {
+	err := session.Wait()
+
+	value, ok := runningCommands.LoadAndDelete(session)
+	if !ok {
+		return err
+	}
+	cmd := value.(runningCommand)
+	status, waitErr := exitStatusOf(err)
+
+	cmd.span.SetAttribute("ssh.exit_status", status)
+	cmd.span.End(waitErr)
+	if ssh_conf.Metrics != nil {
+		ssh_conf.Metrics.CommandFinished(ssh_conf, ssh_conf.clock().Now().Sub(cmd.started), status)
+	}
+	return err
+}
+
+func (ssh_conf *MakeConfig) bytesTransferred(direction string, n int64) {
+	if ssh_conf.Metrics != nil && n > 0 {
+		ssh_conf.Metrics.BytesTransferred(ssh_conf, direction, n)
+	}
+}
+
+// byteCounter counts the bytes read from r or written to w.
+type byteCounter struct {
+	r io.Reader
+	w io.Writer
+	n int64
+}
+
+func (c *byteCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,109 @@
+package easyssh
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testMetrics struct {
+	mu       sync.Mutex
+	failed   int
+	started  int
+	finished int
+}
+
+func (m *testMetrics) ConnectionOpened(host *MakeConfig, authMethod string, duration time.Duration) {}
+func (m *testMetrics) ConnectionFailed(host *MakeConfig, err error)                                 { m.failed++ }
+func (m *testMetrics) CommandStarted(host *MakeConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.started++
+}
+func (m *testMetrics) CommandFinished(host *MakeConfig, duration time.Duration, exitStatus int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.finished++
+}
+func (m *testMetrics) BytesTransferred(host *MakeConfig, direction string, n int64) {}
+
+func TestMetricsConnectionFailed(t *testing.T) {
+	metrics := &testMetrics{}
+	cfg := &MakeConfig{Server: "example.com", Port: "22", User: "test", Password: "secret", Metrics: metrics,
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, errors.New("network down")
+		}}
+
+	if _, err := cfg.Connect(); err == nil {
+		t.Fatal("Expected connect error")
+	}
+	if metrics.failed != 1 {
+		t.Errorf("Expected 1 failed connection, got %d", metrics.failed)
+	}
+}
+
+func (m *testMetrics) running() (started, finished int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.started, m.finished
+}
+
+func TestMetricsStreamRawFinished(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	metrics := &testMetrics{}
+	cfg.Metrics = metrics
+
+	output, done, err := cfg.StreamRaw("echo hello")
+	if err != nil {
+		t.Fatalf("Error streaming: %s", err)
+	}
+	for finished := false; !finished; {
+		select {
+		case <-output:
+		case <-done:
+			finished = true
+		}
+	}
+
+	if started, finished := metrics.running(); started != 1 || finished != 1 {
+		t.Errorf("Expected 1 started and 1 finished command, got %d and %d", started, finished)
+	}
+}
+
+func TestMetricsFailedUploadFinished(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	metrics := &testMetrics{}
+	cfg.Metrics = metrics
+	srv.Handle("scp -t '/full'", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		stdout.Write([]byte{0})
+		r := bufio.NewReader(stdin)
+		r.ReadString('\n')
+		io.WriteString(stdout, "\x02scp: /full: No space left on device\n")
+		io.Copy(ioutil.Discard, r)
+		return 1
+	})
+
+	src, err := ioutil.TempFile("", "easyssh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(src.Name())
+	src.WriteString("data")
+	src.Close()
+
+	if err := cfg.Upload(src.Name(), "/full"); err == nil {
+		t.Fatal("Expected upload to fail")
+	}
+	if started, finished := metrics.running(); started != 1 || finished != 1 {
+		t.Errorf("Expected 1 started and 1 finished command, got %d and %d", started, finished)
+	}
+}
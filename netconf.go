@@ -0,0 +1,255 @@
+package easyssh
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// NetconfPort is the port NETCONF servers listen on by default.
+const NetconfPort = "830"
+
+const (
+	netconfBase10 = "urn:ietf:params:netconf:base:1.0"
+	netconfBase11 = "urn:ietf:params:netconf:base:1.1"
+	netconfEOM    = "]]>]]>"
+)
+
+// NetconfSession is a NETCONF session (RFC 6241) running over the "netconf"
+// SSH subsystem (RFC 6242). Depending on the capabilities announced by the
+// server, messages are framed using the end-of-message marker (base:1.0) or
+// chunked framing (base:1.1).
+type NetconfSession struct {
+	// SessionID is the session-id assigned by the server.
+	SessionID string
+	// ServerCapabilities are the capabilities announced in the server's hello.
+	ServerCapabilities []string
+
+	subsystem *Subsystem
+	reader    *bufio.Reader
+	chunked   bool
+	messageID uint64
+}
+
+type netconfHello struct {
+	XMLName      xml.Name `xml:"hello"`
+	Capabilities []string `xml:"capabilities>capability"`
+	SessionID    string   `xml:"session-id"`
+}
+
+type netconfReply struct {
+	XMLName   xml.Name       `xml:"rpc-reply"`
+	MessageID string         `xml:"message-id,attr"`
+	Errors    []NetconfError `xml:"rpc-error"`
+}
+
+// NetconfError is an <rpc-error> reported by a NETCONF server.
+type NetconfError struct {
+	Type     string `xml:"error-type"`
+	Tag      string `xml:"error-tag"`
+	Severity string `xml:"error-severity"`
+	Message  string `xml:"error-message"`
+}
+
+func (e *NetconfError) Error() string {
+	msg := strings.TrimSpace(e.Message)
+	if msg == "" {
+		msg = e.Tag
+	}
+	return fmt.Sprintf("NETCONF %s %s: %s", e.Type, e.Severity, msg)
+}
+
+// Netconf opens the "netconf" subsystem on the remote machine and exchanges
+// hello messages with the server. Note that NETCONF servers usually listen on
+// NetconfPort instead of the SSH default port.
+func (ssh_conf *MakeConfig) Netconf() (*NetconfSession, error) {
+	subsystem, err := ssh_conf.Subsystem("netconf")
+	if err != nil {
+		return nil, err
+	}
+	return newNetconfSession(subsystem)
+}
+
+// Netconf opens the "netconf" subsystem in a new session on the connection and
+// exchanges hello messages with the server.
+func (c *Client) Netconf() (*NetconfSession, error) {
+	subsystem, err := c.Subsystem("netconf")
+	if err != nil {
+		return nil, err
+	}
+	return newNetconfSession(subsystem)
+}
+
+// newNetconfSession exchanges hello messages on subsystem, which is closed if
+// this fails.
+func newNetconfSession(subsystem *Subsystem) (*NetconfSession, error) {
+	n := &NetconfSession{
+		subsystem: subsystem,
+		reader:    bufio.NewReader(subsystem),
+	}
+	if err := n.hello(); err != nil {
+		subsystem.Close()
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// exchanges hello messages and switches to chunked framing if supported by both
+// sides
+func (n *NetconfSession) hello() error {
+	hello := xml.Header + `<hello xmlns="` + netconfBase10 + `"><capabilities>` +
+		`<capability>` + netconfBase10 + `</capability>` +
+		`<capability>` + netconfBase11 + `</capability>` +
+		`</capabilities></hello>`
+	if err := writeNetconfEOM(n.subsystem, []byte(hello)); err != nil {
+		return err
+	}
+
+	msg, err := readNetconfEOM(n.reader)
+	if err != nil {
+		return err
+	}
+
+	var serverHello netconfHello
+	if err := xml.Unmarshal(msg, &serverHello); err != nil {
+		return fmt.Errorf("Error parsing NETCONF hello: %s", err)
+	}
+	n.SessionID = strings.TrimSpace(serverHello.SessionID)
+	for _, capability := range serverHello.Capabilities {
+		capability = strings.TrimSpace(capability)
+		n.ServerCapabilities = append(n.ServerCapabilities, capability)
+		if capability == netconfBase11 {
+			n.chunked = true
+		}
+	}
+
+	return nil
+}
+
+// Send sends the raw message msg (ex. a complete <rpc> element) using the
+// negotiated framing. Use RPC for the usual request and reply. A session must
+// not be used by multiple goroutines at once.
+func (n *NetconfSession) Send(msg []byte) error {
+	if n.chunked {
+		return writeNetconfChunked(n.subsystem, msg)
+	}
+	return writeNetconfEOM(n.subsystem, msg)
+}
+
+// Receive reads the next raw message sent by the server (ex. an <rpc-reply> or
+// a <notification>), with the framing removed.
+func (n *NetconfSession) Receive() ([]byte, error) {
+	if n.chunked {
+		return readNetconfChunked(n.reader)
+	}
+	return readNetconfEOM(n.reader)
+}
+
+// RPC sends operation (ex. "<get-config><source><running/></source></get-config>")
+// wrapped into an <rpc> element with a new message-id and returns the raw
+// <rpc-reply> received for it. If the server replied with an <rpc-error>, it is
+// returned as *NetconfError.
+func (n *NetconfSession) RPC(operation string) (reply string, err error) {
+	n.messageID++
+	id := strconv.FormatUint(n.messageID, 10)
+
+	rpc := `<rpc message-id="` + id + `" xmlns="` + netconfBase10 + `">` + operation + `</rpc>`
+	if err := n.Send([]byte(rpc)); err != nil {
+		return "", err
+	}
+
+	msg, err := n.Receive()
+	if err != nil {
+		return "", err
+	}
+
+	var parsed netconfReply
+	if err := xml.Unmarshal(msg, &parsed); err != nil {
+		return "", fmt.Errorf("Error parsing NETCONF reply: %s", err)
+	}
+	if parsed.MessageID != id {
+		return "", fmt.Errorf("Unexpected NETCONF reply with message-id '%s', expected '%s'", parsed.MessageID, id)
+	}
+	for _, rpcErr := range parsed.Errors {
+		if rpcErr.Severity != "warning" {
+			return string(msg), &rpcErr
+		}
+	}
+
+	return string(msg), nil
+}
+
+// Close ends the NETCONF session gracefully and closes the SSH session.
+func (n *NetconfSession) Close() error {
+	_, err := n.RPC("<close-session/>")
+	n.subsystem.Close()
+	return err
+}
+
+// writes msg followed by the base:1.0 end-of-message marker
+func writeNetconfEOM(w io.Writer, msg []byte) error {
+	_, err := io.WriteString(w, string(msg)+netconfEOM)
+	return err
+}
+
+// reads a message terminated by the base:1.0 end-of-message marker
+func readNetconfEOM(r *bufio.Reader) ([]byte, error) {
+	var msg []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		msg = append(msg, b)
+		if bytes.HasSuffix(msg, []byte(netconfEOM)) {
+			return bytes.TrimSpace(msg[:len(msg)-len(netconfEOM)]), nil
+		}
+	}
+}
+
+// writes msg as a single chunk using base:1.1 chunked framing
+func writeNetconfChunked(w io.Writer, msg []byte) error {
+	_, err := fmt.Fprintf(w, "\n#%d\n%s\n##\n", len(msg), msg)
+	return err
+}
+
+// reads a message in base:1.1 chunked framing
+func readNetconfChunked(r *bufio.Reader) ([]byte, error) {
+	var msg []byte
+	for {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if header == "\n" {
+			// every chunk header starts with a newline
+			header, err = r.ReadString('\n')
+			if err != nil {
+				return nil, err
+			}
+		}
+		header = strings.TrimSuffix(header, "\n")
+
+		if header == "##" {
+			return msg, nil
+		}
+		if !strings.HasPrefix(header, "#") {
+			return nil, fmt.Errorf("Invalid NETCONF chunk header: '%s'", header)
+		}
+		size, err := strconv.ParseUint(header[1:], 10, 32)
+		if err != nil || size == 0 {
+			return nil, fmt.Errorf("Invalid NETCONF chunk size: '%s'", header[1:])
+		}
+
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return nil, err
+		}
+		msg = append(msg, chunk...)
+	}
+}
@@ -0,0 +1,117 @@
+package easyssh
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestNetconfEOMFraming(t *testing.T) {
+	var buf bytes.Buffer
+	for _, msg := range []string{"<hello/>", "<rpc-reply/>"} {
+		if err := writeNetconfEOM(&buf, []byte(msg)); err != nil {
+			t.Fatalf("Error writing message: %s", err)
+		}
+	}
+
+	r := bufio.NewReader(&buf)
+	for _, expected := range []string{"<hello/>", "<rpc-reply/>"} {
+		msg, err := readNetconfEOM(r)
+		if err != nil {
+			t.Fatalf("Error reading message: %s", err)
+		}
+		if string(msg) != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, msg)
+		}
+	}
+}
+
+func TestNetconfChunkedFraming(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeNetconfChunked(&buf, []byte("<rpc/>")); err != nil {
+		t.Fatalf("Error writing message: %s", err)
+	}
+	if expected := "\n#6\n<rpc/>\n##\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	// example from RFC 6242, section 4.2, followed by a second message
+	input := "\n#4\n<rpc\n#18\n message-id=\"102\"\n\n#79\n     xmlns=\"urn:ietf:params:xml:ns:netconf:base:1.0\">\n  <close-session/>\n</rpc>\n##\n" +
+		"\n#3\nabc\n##\n"
+	r := bufio.NewReader(strings.NewReader(input))
+
+	msg, err := readNetconfChunked(r)
+	if err != nil {
+		t.Fatalf("Error reading message: %s", err)
+	}
+	expected := "<rpc message-id=\"102\"\n     xmlns=\"urn:ietf:params:xml:ns:netconf:base:1.0\">\n  <close-session/>\n</rpc>"
+	if string(msg) != expected {
+		t.Errorf("Expected %q, got %q", expected, msg)
+	}
+
+	msg, err = readNetconfChunked(r)
+	if err != nil {
+		t.Fatalf("Error reading message: %s", err)
+	}
+	if string(msg) != "abc" {
+		t.Errorf("Expected %q, got %q", "abc", msg)
+	}
+
+	if _, err := readNetconfChunked(bufio.NewReader(strings.NewReader("\n#x\nabc\n##\n"))); err == nil {
+		t.Errorf("Expected error for invalid chunk size")
+	}
+}
+
+func TestClientNetconf(t *testing.T) {
+	srv, client := connectTestServer(t)
+	defer srv.Close()
+	defer client.Close()
+
+	// a base:1.0 server replying to every rpc with <ok/>
+	srv.HandleSubsystem("netconf", func(name string, stdin io.Reader, stdout, stderr io.Writer) int {
+		writeNetconfEOM(stdout, []byte(`<hello xmlns="`+netconfBase10+`"><capabilities>`+
+			`<capability>`+netconfBase10+`</capability></capabilities><session-id>4</session-id></hello>`))
+		r := bufio.NewReader(stdin)
+		if _, err := readNetconfEOM(r); err != nil {
+			return 1
+		}
+		for {
+			msg, err := readNetconfEOM(r)
+			if err != nil {
+				return 0
+			}
+			id := regexp.MustCompile(`message-id="([^"]+)"`).FindSubmatch(msg)
+			writeNetconfEOM(stdout, []byte(`<rpc-reply message-id="`+string(id[1])+`"><ok/></rpc-reply>`))
+			if bytes.Contains(msg, []byte("<close-session/>")) {
+				return 0
+			}
+		}
+	})
+
+	n, err := client.Netconf()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.SessionID != "4" {
+		t.Errorf("Expected session-id 4, got '%s'", n.SessionID)
+	}
+
+	reply, err := n.RPC("<lock><target><candidate/></target></lock>")
+	if err != nil || !strings.Contains(reply, "<ok/>") {
+		t.Errorf("Expected <ok/> reply, got %q (%v)", reply, err)
+	}
+
+	if err := n.Send([]byte(`<rpc message-id="custom"><commit/></rpc>`)); err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := n.Receive(); err != nil || string(msg) != `<rpc-reply message-id="custom"><ok/></rpc-reply>` {
+		t.Errorf("Expected raw reply, got %q (%v)", msg, err)
+	}
+
+	if err := n.Close(); err != nil {
+		t.Errorf("Expected session to be closed gracefully, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+// Package otelssh traces the connections, commands and transfers of easyssh
+// using OpenTelemetry.
+//
+//	host.Tracer = otelssh.NewTracer(ctx, otel.Tracer("deploy"))
+package otelssh
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/hypersleep/easyssh.v0"
+)
+
+// Tracer implements easyssh.Tracer using an OpenTelemetry tracer. As the
+// functions of easyssh take no context, the spans are started as children of
+// the span in the context given to NewTracer.
+type Tracer struct {
+	ctx    context.Context
+	tracer trace.Tracer
+}
+
+// NewTracer returns a Tracer starting spans using tracer as children of the
+// span in ctx.
+func NewTracer(ctx context.Context, tracer trace.Tracer) *Tracer {
+	return &Tracer{ctx: ctx, tracer: tracer}
+}
+
+// Start implements easyssh.Tracer.
+func (t *Tracer) Start(host *easyssh.MakeConfig, operation string) easyssh.Span {
+	_, span := t.tracer.Start(t.ctx, operation, trace.WithSpanKind(trace.SpanKindClient))
+	return otelSpan{span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetAttribute(key string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		s.span.SetAttributes(attribute.String(key, v))
+	case int:
+		s.span.SetAttributes(attribute.Int(key, v))
+	case int64:
+		s.span.SetAttributes(attribute.Int64(key, v))
+	}
+}
+
+func (s otelSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+var _ easyssh.Tracer = (*Tracer)(nil)
@@ -0,0 +1,40 @@
+package otelssh
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gopkg.in/hypersleep/easyssh.v0"
+)
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := NewTracer(context.Background(), provider.Tracer("test"))
+
+	span := tracer.Start(&easyssh.MakeConfig{Server: "example.com"}, "ssh.command")
+	span.SetAttribute("ssh.command", "uptime")
+	span.SetAttribute("ssh.exit_status", 1)
+	span.End(errors.New("failed"))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	s := spans[0]
+	if s.Name() != "ssh.command" || s.Status().Code != codes.Error {
+		t.Errorf("Expected failed ssh.command span, got %s (%v)", s.Name(), s.Status())
+	}
+
+	expected := []attribute.KeyValue{attribute.String("ssh.command", "uptime"), attribute.Int("ssh.exit_status", 1)}
+	for i, attr := range expected {
+		if i >= len(s.Attributes()) || s.Attributes()[i] != attr {
+			t.Errorf("Expected attribute %v, got %v", attr, s.Attributes())
+		}
+	}
+}
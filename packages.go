@@ -0,0 +1,126 @@
+package easyssh
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var packageNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.+:~=\-]*$`)
+
+// ErrNoPackageManager is returned if no supported package manager was found on
+// the remote machine.
+var ErrNoPackageManager = errors.New("No supported package manager found")
+
+// PackageManager is a package manager of a Linux distribution.
+type PackageManager string
+
+const (
+	PackageManagerApt    PackageManager = "apt"
+	PackageManagerDnf    PackageManager = "dnf"
+	PackageManagerYum    PackageManager = "yum"
+	PackageManagerApk    PackageManager = "apk"
+	PackageManagerZypper PackageManager = "zypper"
+)
+
+// packageCommands are the shell commands to check whether the package "$p" is
+// installed, and to install and remove packages non-interactively.
+var packageCommands = map[PackageManager]struct{ installed, install, remove string }{
+	PackageManagerApt: {
+		`dpkg-query -W -f='${Status}' "$p" 2>/dev/null | grep -q 'install ok installed'`,
+		"DEBIAN_FRONTEND=noninteractive apt-get install -y",
+		"DEBIAN_FRONTEND=noninteractive apt-get remove -y",
+	},
+	PackageManagerDnf:    {`rpm -q "$p" >/dev/null 2>&1`, "dnf install -y", "dnf remove -y"},
+	PackageManagerYum:    {`rpm -q "$p" >/dev/null 2>&1`, "yum install -y", "yum remove -y"},
+	PackageManagerApk:    {`apk info -e "$p" >/dev/null 2>&1`, "apk add", "apk del"},
+	PackageManagerZypper: {`rpm -q "$p" >/dev/null 2>&1`, "zypper --non-interactive install", "zypper --non-interactive remove"},
+}
+
+// DetectPackageManager returns the package manager of the remote machine, or
+// ErrNoPackageManager if none of apt, dnf, yum, apk and zypper is found.
+func (ssh_conf *MakeConfig) DetectPackageManager() (PackageManager, error) {
+	stdout, _, err := ssh_conf.capture("for pm in apt-get dnf yum apk zypper; do " +
+		`command -v "$pm" >/dev/null 2>&1 && { echo "$pm"; exit 0; }; done; exit 1`)
+	if err != nil {
+		var cmdErr *CommandError
+		if errors.As(err, &cmdErr) {
+			return "", ErrNoPackageManager
+		}
+		return "", err
+	}
+
+	name := strings.TrimSpace(string(stdout))
+	if name == "apt-get" {
+		return PackageManagerApt, nil
+	}
+	return PackageManager(name), nil
+}
+
+// packagesMatching returns the names whose installed state is installed.
+func (ssh_conf *MakeConfig) packagesMatching(pm PackageManager, names []string, installed bool) ([]string, error) {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		if !packageNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("Invalid package name: '%s'", name)
+		}
+		quoted[i] = shellQuote(name)
+	}
+
+	negate := "!"
+	if installed {
+		negate = ""
+	}
+	command := "for p in " + strings.Join(quoted, " ") + "; do if " + negate + " " +
+		packageCommands[pm].installed + `; then echo "$p"; fi; done`
+
+	stdout, stderr, err := ssh_conf.capture(command)
+	if err != nil {
+		return nil, remoteError(stderr, err)
+	}
+	return strings.Fields(string(stdout)), nil
+}
+
+// InstallPackages installs the packages which are not installed yet using the
+// package manager of the remote machine. This needs root privileges (see
+// Escalation).
+func (ssh_conf *MakeConfig) InstallPackages(names ...string) error {
+	return ssh_conf.changePackages(names, false)
+}
+
+// RemovePackages removes the packages which are installed using the package
+// manager of the remote machine. This needs root privileges (see Escalation).
+func (ssh_conf *MakeConfig) RemovePackages(names ...string) error {
+	return ssh_conf.changePackages(names, true)
+}
+
+// installs (or removes, if remove is set) the packages which need it
+func (ssh_conf *MakeConfig) changePackages(names []string, remove bool) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	pm, err := ssh_conf.DetectPackageManager()
+	if err != nil {
+		return err
+	}
+
+	pending, err := ssh_conf.packagesMatching(pm, names, remove)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	command, action := packageCommands[pm].install, "installing"
+	if remove {
+		command, action = packageCommands[pm].remove, "removing"
+	}
+	for _, name := range pending {
+		command += " " + shellQuote(name)
+	}
+
+	if err := ssh_conf.privileged(command); err != nil {
+		return fmt.Errorf("Error %s packages %s with %s: %s", action, strings.Join(pending, ", "), pm, err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package easyssh
+
+import "testing"
+
+func TestPackageCommands(t *testing.T) {
+	for _, pm := range []PackageManager{PackageManagerApt, PackageManagerDnf, PackageManagerYum, PackageManagerApk, PackageManagerZypper} {
+		commands, ok := packageCommands[pm]
+		if !ok || commands.installed == "" || commands.install == "" || commands.remove == "" {
+			t.Errorf("Expected commands for %s", pm)
+		}
+	}
+}
+
+func TestPackageNameValidation(t *testing.T) {
+	cfg := &MakeConfig{}
+	if _, err := cfg.packagesMatching(PackageManagerApt, []string{"nginx", "$(reboot)"}, false); err == nil {
+		t.Errorf("Expected error for invalid package name")
+	}
+
+	for _, name := range []string{"nginx", "libc6:amd64", "g++", "python3.11", "nginx=1.18.0-6"} {
+		if !packageNameRegex.MatchString(name) {
+			t.Errorf("Expected '%s' to be a valid package name", name)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package easyssh
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrPoolClosed is returned when using a Pool after Close.
+var ErrPoolClosed = errors.New("Pool closed")
+
+// Pool keeps one connection per host open and runs all sessions to that host
+// over it, like OpenSSH's ControlMaster. Hosts are identified by their
+// connection string (see MakeConfig.String). A connection which broke is
+// replaced transparently when the next session is opened.
+// MaxSessionsPerHost limits the sessions open on a connection at the same
+// time, further ones wait for a free slot. OpenSSH allows 10 by default
+// (MaxSessions). IdleTimeout closes connections without sessions after the
+// given time. Zero values mean no limit.
+type Pool struct {
+	MaxSessionsPerHost int
+	IdleTimeout        time.Duration
+
+	mu     sync.Mutex
+	conns  map[string]*poolConn
+	closed bool
+}
+
+// poolConn is the connection of a Pool to a single host.
+type poolConn struct {
+	mu       sync.Mutex
+	client   *Client
+	slots    chan struct{}
+	sessions int
+	idle     *time.Timer
+}
+
+// NewPool returns a Pool with the given limits.
+func NewPool(maxSessionsPerHost int, idleTimeout time.Duration) *Pool {
+	return &Pool{MaxSessionsPerHost: maxSessionsPerHost, IdleTimeout: idleTimeout}
+}
+
+// returns the connection entry for host, creating it if needed
+func (p *Pool) conn(host *MakeConfig) (*poolConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+	if p.conns == nil {
+		p.conns = map[string]*poolConn{}
+	}
+
+	key := host.String()
+	c := p.conns[key]
+	if c == nil {
+		c = &poolConn{}
+		if p.MaxSessionsPerHost > 0 {
+			c.slots = make(chan struct{}, p.MaxSessionsPerHost)
+		}
+		p.conns[key] = c
+	}
+	return c, nil
+}
+
+// NewSession opens a session to host over the pooled connection, connecting
+// first if needed. The returned function closes the session and needs to be
+// called once it is not used anymore.
+func (p *Pool) NewSession(host *MakeConfig) (*ssh.Session, func(), error) {
+	c, err := p.conn(host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if c.slots != nil {
+		c.slots <- struct{}{}
+	}
+	session, err := c.newSession(host)
+	if err != nil {
+		if c.slots != nil {
+			<-c.slots
+		}
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			session.Close()
+			c.release(p.IdleTimeout)
+			if c.slots != nil {
+				<-c.slots
+			}
+		})
+	}
+	return session, release, nil
+}
+
+// newSession opens a session on the connection, reconnecting once if the
+// connection broke.
+func (c *poolConn) newSession(host *MakeConfig) (*ssh.Session, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.idle != nil {
+		c.idle.Stop()
+		c.idle = nil
+	}
+
+	for attempt := 0; ; attempt++ {
+		if c.client == nil {
+			client, err := host.Connect()
+			if err != nil {
+				return nil, err
+			}
+			c.client = client
+		}
+
+		session, err := c.client.NewSession()
+		if err == nil {
+			c.sessions++
+			return session, nil
+		}
+
+		host.logf(LogDebug1, "Pooled connection to %s broke, reconnecting: %s", host.address(), err)
+		c.client.Close()
+		c.client = nil
+		if attempt > 0 {
+			return nil, err
+		}
+	}
+}
+
+// release marks a session as closed and schedules closing the connection once
+// it is idle for idleTimeout.
+func (c *poolConn) release(idleTimeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sessions--
+	if c.sessions > 0 || idleTimeout <= 0 || c.client == nil {
+		return
+	}
+
+	client := c.client
+	c.idle = time.AfterFunc(idleTimeout, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.sessions == 0 && c.client == client {
+			client.config.logf(LogDebug1, "Closing idle pooled connection to %s", client.config.address())
+			client.Close()
+			c.client = nil
+		}
+	})
+}
+
+// RunTo runs command on host over the pooled connection like MakeConfig.RunTo.
+func (p *Pool) RunTo(host *MakeConfig, command string, stdout, stderr io.Writer) (exitStatus int, err error) {
+	session, release, err := p.NewSession(host)
+	if err != nil {
+		return -1, err
+	}
+	defer release()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	return exitStatusOf(host.run(session, command))
+}
+
+// Run runs command on host over the pooled connection and returns its combined
+// stdout and stderr. Like Client.Run, a *CommandError is returned if the
+// command fails.
+func (p *Pool) Run(host *MakeConfig, command string) (string, error) {
+	var output bytes.Buffer
+	w := &lockedWriter{w: &output}
+
+	status, err := p.RunTo(host, command, w, w)
+	if err == nil && status != 0 {
+		err = &CommandError{Command: command, ExitStatus: status}
+	}
+	return output.String(), err
+}
+
+// Close closes all connections of the pool. Sessions still open are closed
+// with them.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	for key, c := range p.conns {
+		c.mu.Lock()
+		if c.idle != nil {
+			c.idle.Stop()
+		}
+		if c.client != nil {
+			c.client.Close()
+			c.client = nil
+		}
+		c.mu.Unlock()
+		delete(p.conns, key)
+	}
+	return nil
+}
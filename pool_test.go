@@ -0,0 +1,42 @@
+package easyssh
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestPoolKeysByHost(t *testing.T) {
+	p := NewPool(2, 0)
+	a, _ := p.conn(&MakeConfig{User: "root", Server: "example.com", Port: "22"})
+	b, _ := p.conn(&MakeConfig{User: "root", Server: "example.com", Port: "22"})
+	c, _ := p.conn(&MakeConfig{User: "admin", Server: "example.com", Port: "22"})
+
+	if a != b || a == c {
+		t.Errorf("Expected connections to be shared per user, host and port")
+	}
+	if cap(a.slots) != 2 {
+		t.Errorf("Expected 2 session slots, got %d", cap(a.slots))
+	}
+}
+
+func TestPoolConnectError(t *testing.T) {
+	p := NewPool(1, 0)
+	host := &MakeConfig{Server: "example.com", Port: "22", User: "test", Password: "secret",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, errors.New("network down")
+		}}
+
+	// a failed attempt must not keep the only session slot
+	for i := 0; i < 2; i++ {
+		if _, _, err := p.NewSession(host); err == nil {
+			t.Errorf("Expected connect error")
+		}
+	}
+
+	p.Close()
+	if _, err := p.Run(host, "true"); err != ErrPoolClosed {
+		t.Errorf("Expected ErrPoolClosed, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package easyssh
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var sysctlNameRegex = regexp.MustCompile(`^[A-Za-z0-9_\-]+(\.[A-Za-z0-9_\-]+)*$`)
+
+// ReadProc returns the contents of a file below /proc on the remote machine
+// (ex. ReadProc("/proc/loadavg") or ReadProc("meminfo")). A missing entry is
+// reported as an error satisfying os.IsNotExist, an unreadable one as an error
+// satisfying os.IsPermission.
+func (ssh_conf *MakeConfig) ReadProc(name string) (string, error) {
+	file, err := procPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	stdout, stderr, err := ssh_conf.capture("cat " + shellQuote(file))
+	if err != nil {
+		return "", remoteFileError("readproc", file, stderr, err)
+	}
+
+	return string(stdout), nil
+}
+
+// Sysctl returns the current value of a Linux kernel parameter on the remote
+// machine (ex. Sysctl("net.ipv4.ip_forward")), as read from /proc/sys.
+func (ssh_conf *MakeConfig) Sysctl(name string) (string, error) {
+	file, err := sysctlPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := ssh_conf.ReadProc(file)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(value), nil
+}
+
+// procPath returns the absolute path of a /proc entry, which may be given with
+// or without the leading "/proc/".
+func procPath(name string) (string, error) {
+	file := name
+	if !strings.HasPrefix(file, "/") {
+		file = "/proc/" + file
+	}
+	file = path.Clean(file)
+
+	if !strings.HasPrefix(file, "/proc/") {
+		return "", fmt.Errorf("Not a /proc entry: '%s'", name)
+	}
+
+	return file, nil
+}
+
+// sysctlPath maps a kernel parameter name to its /proc/sys path.
+func sysctlPath(name string) (string, error) {
+	if !sysctlNameRegex.MatchString(name) {
+		return "", fmt.Errorf("Invalid sysctl name: '%s'", name)
+	}
+
+	return "/proc/sys/" + strings.Replace(name, ".", "/", -1), nil
+}
+
+// remoteFileError translates the error messages of common remote file
+// utilities to the errors of package os.
+func remoteFileError(op, file string, stderr []byte, err error) error {
+	msg := string(stderr)
+
+	switch {
+	case strings.Contains(msg, "No such file or directory"):
+		return &os.PathError{Op: op, Path: file, Err: os.ErrNotExist}
+	case strings.Contains(msg, "Permission denied"):
+		return &os.PathError{Op: op, Path: file, Err: os.ErrPermission}
+	case strings.TrimSpace(msg) != "":
+		return &os.PathError{Op: op, Path: file, Err: errors.New(strings.TrimSpace(msg))}
+	}
+
+	return &os.PathError{Op: op, Path: file, Err: err}
+}
@@ -0,0 +1,60 @@
+package easyssh
+
+import "testing"
+
+func TestProcPath(t *testing.T) {
+	testCases := map[string]string{
+		"loadavg":              "/proc/loadavg",
+		"/proc/meminfo":        "/proc/meminfo",
+		"sys/kernel/hostname":  "/proc/sys/kernel/hostname",
+		"/proc/1/../self/stat": "/proc/self/stat",
+	}
+
+	for input, expected := range testCases {
+		result, err := procPath(input)
+		if err != nil {
+			t.Errorf("Error mapping '%s': %s", input, err)
+		}
+		if result != expected {
+			t.Errorf("Expected '%s' for '%s', got '%s'", expected, input, result)
+		}
+	}
+
+	for _, input := range []string{"/etc/passwd", "../etc/passwd", "/proc/../etc/shadow", "/proc"} {
+		if result, err := procPath(input); err == nil {
+			t.Errorf("Expected error for '%s', got '%s'", input, result)
+		}
+	}
+}
+
+func TestSysctlPath(t *testing.T) {
+	result, err := sysctlPath("net.ipv4.ip_forward")
+	if err != nil {
+		t.Errorf("Error mapping sysctl name: %s", err)
+	}
+	if expected := "/proc/sys/net/ipv4/ip_forward"; result != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, result)
+	}
+
+	for _, input := range []string{"", "kernel..hostname", "kernel/hostname", "../../etc/passwd", "kernel.hostname; rm -rf /"} {
+		if result, err := sysctlPath(input); err == nil {
+			t.Errorf("Expected error for '%s', got '%s'", input, result)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	testCases := map[string]string{
+		"":            "''",
+		"simple":      "'simple'",
+		"with space":  "'with space'",
+		"it's":        `'it'\''s'`,
+		"$(rm -rf /)": "'$(rm -rf /)'",
+	}
+
+	for input, expected := range testCases {
+		if result := shellQuote(input); result != expected {
+			t.Errorf("Expected %s for '%s', got %s", expected, input, result)
+		}
+	}
+}
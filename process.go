@@ -0,0 +1,89 @@
+package easyssh
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var signalNameRegex = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+// Process is an entry of the process list of the remote machine. CPU and Mem
+// are the percentages of CPU time and physical memory used, as shown by ps.
+type Process struct {
+	PID     int
+	User    string
+	CPU     float64
+	Mem     float64
+	Command string
+}
+
+// Processes returns the processes running on the remote machine, as listed by
+// ps.
+func (ssh_conf *MakeConfig) Processes() ([]Process, error) {
+	stdout, stderr, err := ssh_conf.capture("ps -eo pid=,user=,pcpu=,pmem=,args=")
+	if err != nil {
+		return nil, remoteError(stderr, err)
+	}
+
+	return parseProcesses(string(stdout))
+}
+
+// parseProcesses parses the output of ps -eo pid=,user=,pcpu=,pmem=,args=.
+func parseProcesses(output string) ([]Process, error) {
+	var processes []Process
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("Error parsing process list: %q", line)
+		}
+
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing process list: %q", line)
+		}
+		cpu, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing process list: %q", line)
+		}
+		mem, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing process list: %q", line)
+		}
+
+		processes = append(processes, Process{
+			PID:     pid,
+			User:    fields[1],
+			CPU:     cpu,
+			Mem:     mem,
+			Command: strings.Join(fields[4:], " "),
+		})
+	}
+
+	return processes, nil
+}
+
+// Kill sends signal (ex. ssh.SIGTERM) to the process pid on the remote
+// machine.
+func (ssh_conf *MakeConfig) Kill(pid int, signal ssh.Signal) error {
+	if pid <= 0 {
+		return fmt.Errorf("Invalid pid: %d", pid)
+	}
+	if !signalNameRegex.MatchString(string(signal)) {
+		return fmt.Errorf("Invalid signal: '%s'", signal)
+	}
+
+	_, stderr, err := ssh_conf.capture("kill -s " + string(signal) + " " + strconv.Itoa(pid))
+	if err != nil {
+		return remoteError(stderr, err)
+	}
+
+	return nil
+}
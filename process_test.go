@@ -0,0 +1,40 @@
+package easyssh
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParseProcesses(t *testing.T) {
+	output := `    1 root      0.0  0.1 /sbin/init splash
+  812 www-data  12.5  3.4 nginx: worker process
+`
+	processes, err := parseProcesses(output)
+	if err != nil {
+		t.Fatalf("Error parsing processes: %s", err)
+	}
+
+	expected := []Process{
+		{PID: 1, User: "root", CPU: 0, Mem: 0.1, Command: "/sbin/init splash"},
+		{PID: 812, User: "www-data", CPU: 12.5, Mem: 3.4, Command: "nginx: worker process"},
+	}
+	if !reflect.DeepEqual(processes, expected) {
+		t.Errorf("Expected %v, got %v", expected, processes)
+	}
+
+	if _, err := parseProcesses("PID USER %CPU %MEM COMMAND\n"); err == nil {
+		t.Errorf("Expected error for header line")
+	}
+}
+
+func TestKillValidation(t *testing.T) {
+	cfg := &MakeConfig{}
+	if err := cfg.Kill(0, ssh.SIGTERM); err == nil {
+		t.Errorf("Expected error for invalid pid")
+	}
+	if err := cfg.Kill(1, ssh.Signal("TERM; reboot")); err == nil {
+		t.Errorf("Expected error for invalid signal")
+	}
+}
@@ -0,0 +1,132 @@
+package easyssh
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// progressInterval is the minimum time between two progress reports.
+const progressInterval = 100 * time.Millisecond
+
+// Progress describes the state of a running transfer. Total is -1 if the size
+// is not known in advance. Rate is the average speed in bytes per second.
+type Progress struct {
+	Transferred int64
+	Total       int64
+	Rate        float64
+}
+
+// ProgressFunc is called with the progress of a transfer at most every 100ms
+// and once more when the transfer is complete. It may be called from another
+// goroutine than the one transferring.
+type ProgressFunc func(Progress)
+
+// progressTracker counts the bytes of a transfer and reports them to f.
+type progressTracker struct {
+	mu          sync.Mutex
+	f           ProgressFunc
+	clock       Clock
+	start       time.Time
+	last        time.Time
+	total       int64
+	transferred int64
+}
+
+// returns a tracker reporting to f, or nil if f is nil
+func (ssh_conf *MakeConfig) newProgress(f ProgressFunc, total int64) *progressTracker {
+	if f == nil {
+		return nil
+	}
+	now := ssh_conf.clock().Now()
+	return &progressTracker{f: f, clock: ssh_conf.clock(), start: now, last: now, total: total}
+}
+
+func (t *progressTracker) setTotal(total int64) {
+	t.mu.Lock()
+	t.total = total
+	t.mu.Unlock()
+}
+
+// add counts n transferred bytes and reports them if the last report is long
+// enough ago.
+func (t *progressTracker) add(n int) {
+	t.mu.Lock()
+	t.transferred += int64(n)
+	now := t.clock.Now()
+	if now.Sub(t.last) < progressInterval {
+		t.mu.Unlock()
+		return
+	}
+	t.last = now
+	p := t.progress(now)
+	t.mu.Unlock()
+
+	t.f(p)
+}
+
+// finish reports the final progress.
+func (t *progressTracker) finish() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	p := t.progress(t.clock.Now())
+	t.mu.Unlock()
+
+	t.f(p)
+}
+
+// returns the current progress, the caller needs to hold the lock
+func (t *progressTracker) progress(now time.Time) Progress {
+	p := Progress{Transferred: t.transferred, Total: t.total}
+	if elapsed := now.Sub(t.start).Seconds(); elapsed > 0 {
+		p.Rate = float64(t.transferred) / elapsed
+	}
+	return p
+}
+
+// reader returns r counting the bytes read from it, or r itself without
+// tracker.
+func (t *progressTracker) reader(r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	return &progressReader{r, t}
+}
+
+// writer returns w counting the bytes written to it, or w itself without
+// tracker.
+func (t *progressTracker) writer(w io.Writer) io.Writer {
+	if t == nil {
+		return w
+	}
+	return &progressWriter{w, t}
+}
+
+type progressReader struct {
+	r io.Reader
+	t *progressTracker
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.t.add(n)
+	return n, err
+}
+
+type progressWriter struct {
+	w io.Writer
+	t *progressTracker
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.t.add(n)
+	return n, err
+}
+
+// setTotal passes the size of the file received by scp on to the tracker.
+func (w *progressWriter) setTotal(total int64) {
+	w.t.setTotal(total)
+}
@@ -0,0 +1,83 @@
+package easyssh
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowReader advances clock by step for every byte read.
+type slowReader struct {
+	r     io.Reader
+	clock *fakeClock
+	step  time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(p) > 10 {
+		p = p[:10]
+	}
+	n, err := r.r.Read(p)
+	r.clock.now = r.clock.now.Add(time.Duration(n) * r.step)
+	return n, err
+}
+
+func TestProgressReader(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cfg := &MakeConfig{Clock: clock}
+
+	var reports []Progress
+	progress := cfg.newProgress(func(p Progress) { reports = append(reports, p) }, 100)
+
+	src := &slowReader{strings.NewReader(strings.Repeat("x", 100)), clock, 5 * time.Millisecond}
+	if _, err := io.Copy(ioutil.Discard, progress.reader(src)); err != nil {
+		t.Fatal(err)
+	}
+	progress.finish()
+
+	// 10 bytes take 50ms, so every second read is reported, plus the final one
+	if len(reports) != 6 {
+		t.Fatalf("Expected 6 reports, got %d: %v", len(reports), reports)
+	}
+	if reports[0].Transferred != 20 {
+		t.Errorf("Expected first report after 20 bytes, got %d", reports[0].Transferred)
+	}
+	last := reports[len(reports)-1]
+	if last.Transferred != 100 || last.Total != 100 {
+		t.Errorf("Expected 100 of 100 bytes, got %d of %d", last.Transferred, last.Total)
+	}
+	if last.Rate != 200 {
+		t.Errorf("Expected 200 bytes/s, got %f", last.Rate)
+	}
+}
+
+func TestProgressWriterTotal(t *testing.T) {
+	cfg := &MakeConfig{Clock: &fakeClock{now: time.Unix(0, 0)}}
+
+	var last Progress
+	progress := cfg.newProgress(func(p Progress) { last = p }, -1)
+
+	var buf bytes.Buffer
+	w := progress.writer(&buf)
+	setTotal(w, 5)
+	w.Write([]byte("hello"))
+	progress.finish()
+
+	if last.Transferred != 5 || last.Total != 5 {
+		t.Errorf("Expected 5 of 5 bytes, got %d of %d", last.Transferred, last.Total)
+	}
+}
+
+func TestProgressWithoutFunc(t *testing.T) {
+	var cfg MakeConfig
+	progress := cfg.newProgress(nil, 10)
+
+	r := strings.NewReader("abc")
+	if progress.reader(r) != io.Reader(r) {
+		t.Errorf("Expected reader to be unchanged without progress func")
+	}
+	progress.finish()
+}
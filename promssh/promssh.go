@@ -0,0 +1,115 @@
+// Package promssh exposes the connections, commands and transfers of easyssh
+// as Prometheus metrics.
+//
+//	collector := promssh.NewCollector("myservice")
+//	prometheus.MustRegister(collector)
+//	host.Metrics = collector
+package promssh
+
+import (
+	"net"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/hypersleep/easyssh.v0"
+)
+
+// Collector implements easyssh.Metrics and collects the events as Prometheus
+// metrics labeled by host ("host:port").
+type Collector struct {
+	connections      *prometheus.CounterVec
+	connectionErrors *prometheus.CounterVec
+	connectDuration  *prometheus.HistogramVec
+	commandDuration  *prometheus.HistogramVec
+	commands         *prometheus.CounterVec
+	activeCommands   *prometheus.GaugeVec
+	bytesTransferred *prometheus.CounterVec
+}
+
+// NewCollector returns a Collector with metrics named "<namespace>_ssh_...".
+func NewCollector(namespace string) *Collector {
+	opts := func(name, help string) prometheus.Opts {
+		return prometheus.Opts{Namespace: namespace, Subsystem: "ssh", Name: name, Help: help}
+	}
+
+	return &Collector{
+		connections: prometheus.NewCounterVec(prometheus.CounterOpts(opts("connections_total",
+			"Connections opened, by authentication method.")), []string{"host", "auth_method"}),
+		connectionErrors: prometheus.NewCounterVec(prometheus.CounterOpts(opts("connection_errors_total",
+			"Connections which failed.")), []string{"host"}),
+		connectDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "ssh", Name: "connect_duration_seconds",
+			Help: "Time taken to connect and authenticate.", Buckets: prometheus.DefBuckets,
+		}, []string{"host"}),
+		commandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "ssh", Name: "command_duration_seconds",
+			Help: "Time remote commands ran.", Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+		}, []string{"host"}),
+		commands: prometheus.NewCounterVec(prometheus.CounterOpts(opts("commands_total",
+			"Remote commands finished, by whether they succeeded.")), []string{"host", "result"}),
+		activeCommands: prometheus.NewGaugeVec(prometheus.GaugeOpts(opts("active_commands",
+			"Remote commands currently running.")), []string{"host"}),
+		bytesTransferred: prometheus.NewCounterVec(prometheus.CounterOpts(opts("transferred_bytes_total",
+			"Bytes of files transferred, by direction.")), []string{"host", "direction"}),
+	}
+}
+
+func (c *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.connections, c.connectionErrors, c.connectDuration, c.commandDuration,
+		c.commands, c.activeCommands, c.bytesTransferred,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range c.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range c.collectors() {
+		collector.Collect(ch)
+	}
+}
+
+func host(cfg *easyssh.MakeConfig) string {
+	return net.JoinHostPort(cfg.Server, cfg.Port)
+}
+
+// ConnectionOpened implements easyssh.Metrics.
+func (c *Collector) ConnectionOpened(cfg *easyssh.MakeConfig, authMethod string, duration time.Duration) {
+	c.connections.WithLabelValues(host(cfg), authMethod).Inc()
+	c.connectDuration.WithLabelValues(host(cfg)).Observe(duration.Seconds())
+}
+
+// ConnectionFailed implements easyssh.Metrics.
+func (c *Collector) ConnectionFailed(cfg *easyssh.MakeConfig, err error) {
+	c.connectionErrors.WithLabelValues(host(cfg)).Inc()
+}
+
+// CommandStarted implements easyssh.Metrics.
+func (c *Collector) CommandStarted(cfg *easyssh.MakeConfig) {
+	c.activeCommands.WithLabelValues(host(cfg)).Inc()
+}
+
+// CommandFinished implements easyssh.Metrics.
+func (c *Collector) CommandFinished(cfg *easyssh.MakeConfig, duration time.Duration, exitStatus int) {
+	c.activeCommands.WithLabelValues(host(cfg)).Dec()
+	c.commandDuration.WithLabelValues(host(cfg)).Observe(duration.Seconds())
+
+	result := "success"
+	if exitStatus != 0 {
+		result = "failure"
+	}
+	c.commands.WithLabelValues(host(cfg), result).Inc()
+}
+
+// BytesTransferred implements easyssh.Metrics.
+func (c *Collector) BytesTransferred(cfg *easyssh.MakeConfig, direction string, n int64) {
+	c.bytesTransferred.WithLabelValues(host(cfg), direction).Add(float64(n))
+}
+
+var _ easyssh.Metrics = (*Collector)(nil)
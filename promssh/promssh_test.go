@@ -0,0 +1,50 @@
+package promssh
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gopkg.in/hypersleep/easyssh.v0"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector("test")
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(c)
+
+	cfg := &easyssh.MakeConfig{Server: "example.com", Port: "22"}
+	c.ConnectionOpened(cfg, "publickey", 100*time.Millisecond)
+	c.CommandStarted(cfg)
+	c.CommandStarted(cfg)
+	c.CommandFinished(cfg, time.Second, 1)
+	c.BytesTransferred(cfg, "upload", 1024)
+
+	expected := `
+# HELP test_ssh_active_commands Remote commands currently running.
+# TYPE test_ssh_active_commands gauge
+test_ssh_active_commands{host="example.com:22"} 1
+# HELP test_ssh_commands_total Remote commands finished, by whether they succeeded.
+# TYPE test_ssh_commands_total counter
+test_ssh_commands_total{host="example.com:22",result="failure"} 1
+# HELP test_ssh_connections_total Connections opened, by authentication method.
+# TYPE test_ssh_connections_total counter
+test_ssh_connections_total{auth_method="publickey",host="example.com:22"} 1
+# HELP test_ssh_transferred_bytes_total Bytes of files transferred, by direction.
+# TYPE test_ssh_transferred_bytes_total counter
+test_ssh_transferred_bytes_total{direction="upload",host="example.com:22"} 1024
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"test_ssh_active_commands", "test_ssh_commands_total", "test_ssh_connections_total", "test_ssh_transferred_bytes_total")
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHostIPv6(t *testing.T) {
+	if h := host(&easyssh.MakeConfig{Server: "::1", Port: "2222"}); h != "[::1]:2222" {
+		t.Errorf("Expected bracketed IPv6 address, got %s", h)
+	}
+}
@@ -0,0 +1,107 @@
+package easyssh
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/proxy"
+)
+
+// dialProxy opens a connection to addr through the proxy given by ProxyDialer
+// or Proxy.
+func (ssh_conf *MakeConfig) dialProxy(ctx context.Context, addr string) (net.Conn, error) {
+	if ssh_conf.ProxyDialer != nil {
+		if d, ok := ssh_conf.ProxyDialer.(proxy.ContextDialer); ok {
+			return d.DialContext(ctx, "tcp", addr)
+		}
+		return ssh_conf.ProxyDialer.Dial("tcp", addr)
+	}
+
+	u, err := url.Parse(ssh_conf.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing proxy URL: %s", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return dialHTTPConnect(ctx, u, addr)
+	case "socks5", "socks5h":
+		d, err := proxy.FromURL(u, proxy.Direct)
+		if err != nil {
+			return nil, fmt.Errorf("Error creating SOCKS5 dialer: %s", err)
+		}
+		return d.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+	}
+
+	return nil, fmt.Errorf("Unsupported proxy scheme '%s'", u.Scheme)
+}
+
+// dialHTTPConnect opens a tunnel to addr using the HTTP CONNECT method of the
+// proxy at u.
+func dialHTTPConnect(ctx context.Context, u *url.URL, addr string) (net.Conn, error) {
+	proxyAddr := u.Host
+	if u.Port() == "" {
+		if u.Scheme == "https" {
+			proxyAddr = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			proxyAddr = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "https" {
+		conn = tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(u.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Error sending CONNECT request to proxy: %s", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Error reading response of proxy: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("Proxy refused to connect to %s: %s", addr, resp.Status)
+	}
+
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+// bufferedConn returns data the proxy sent after its response (ex. the SSH
+// banner of the server) before reading from the connection again.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
@@ -0,0 +1,82 @@
+package easyssh
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// starts an HTTP proxy answering CONNECT requests with response, followed by
+// the SSH banner of a fake server
+func startTestProxy(t *testing.T, response string) (addr string, requests chan *http.Request) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %s", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	requests = make(chan *http.Request, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			return
+		}
+		requests <- req
+		io.WriteString(conn, response+"SSH-2.0-test\r\n")
+	}()
+
+	return l.Addr().String(), requests
+}
+
+func TestDialHTTPConnect(t *testing.T) {
+	addr, requests := startTestProxy(t, "HTTP/1.1 200 Connection established\r\n\r\n")
+	u, _ := url.Parse("http://john:secret@" + addr)
+
+	conn, err := dialHTTPConnect(context.Background(), u, "example.com:22")
+	if err != nil {
+		t.Fatalf("Error connecting through proxy: %s", err)
+	}
+	defer conn.Close()
+
+	req := <-requests
+	if req.Method != http.MethodConnect || req.Host != "example.com:22" {
+		t.Errorf("Expected CONNECT example.com:22, got %s %s", req.Method, req.Host)
+	}
+	if user, password, ok := req.BasicAuth(); ok || user != "" || password != "" {
+		t.Errorf("Expected credentials in Proxy-Authorization only")
+	}
+	if auth := req.Header.Get("Proxy-Authorization"); auth != "Basic am9objpzZWNyZXQ=" {
+		t.Errorf("Expected proxy credentials, got '%s'", auth)
+	}
+
+	banner, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || banner != "SSH-2.0-test\r\n" {
+		t.Errorf("Expected banner of the server, got %q (%v)", banner, err)
+	}
+}
+
+func TestDialHTTPConnectRefused(t *testing.T) {
+	addr, _ := startTestProxy(t, "HTTP/1.1 403 Forbidden\r\nContent-Length: 0\r\n\r\n")
+	u, _ := url.Parse("http://" + addr)
+
+	if _, err := dialHTTPConnect(context.Background(), u, "example.com:22"); err == nil {
+		t.Errorf("Expected refused CONNECT to fail")
+	}
+}
+
+func TestDialProxyScheme(t *testing.T) {
+	cfg := &MakeConfig{Proxy: "ftp://proxy:21"}
+	if _, err := cfg.dialProxy(context.Background(), "example.com:22"); err == nil {
+		t.Errorf("Expected unsupported scheme to fail")
+	}
+}
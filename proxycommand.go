@@ -0,0 +1,104 @@
+package easyssh
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expandProxyCommand replaces the tokens %h (host), %p (port), %r (user) and
+// %% in the ProxyCommand of MakeConfig, like OpenSSH does.
+func (ssh_conf *MakeConfig) expandProxyCommand() string {
+	port := ssh_conf.Port
+	if port == "" {
+		port = "22"
+	}
+
+	return strings.NewReplacer(
+		"%%", "%",
+		"%h", ssh_conf.Server,
+		"%p", port,
+		"%r", ssh_conf.User,
+	).Replace(ssh_conf.ProxyCommand)
+}
+
+// dialProxyCommand starts the ProxyCommand of MakeConfig and returns a
+// connection using its stdin and stdout. Its stderr is passed on to the one of
+// the local process.
+func (ssh_conf *MakeConfig) dialProxyCommand() (net.Conn, error) {
+	command := ssh_conf.expandProxyCommand()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("/bin/sh", "-c", "exec "+command)
+	}
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	ssh_conf.logf(LogDebug1, "Started ProxyCommand %s", command)
+
+	return &commandConn{cmd: cmd, stdin: stdin, stdout: stdout, addr: proxyCommandAddr(command)}, nil
+}
+
+// commandConn is a net.Conn using the stdin and stdout of a local command.
+// Deadlines are not supported.
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	addr   net.Addr
+
+	closeOnce sync.Once
+}
+
+func (c *commandConn) Read(p []byte) (int, error) {
+	return c.stdout.Read(p)
+}
+
+func (c *commandConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+// Close closes stdin and stops the command.
+func (c *commandConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.stdin.Close()
+		c.cmd.Process.Kill()
+		c.cmd.Wait()
+	})
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr  { return c.addr }
+func (c *commandConn) RemoteAddr() net.Addr { return c.addr }
+
+var errDeadlineUnsupported = errors.New("Deadlines are not supported by ProxyCommand connections")
+
+func (c *commandConn) SetDeadline(t time.Time) error      { return errDeadlineUnsupported }
+func (c *commandConn) SetReadDeadline(t time.Time) error  { return errDeadlineUnsupported }
+func (c *commandConn) SetWriteDeadline(t time.Time) error { return errDeadlineUnsupported }
+
+// proxyCommandAddr is the net.Addr of a ProxyCommand connection.
+type proxyCommandAddr string
+
+func (a proxyCommandAddr) Network() string { return "proxycommand" }
+func (a proxyCommandAddr) String() string  { return string(a) }
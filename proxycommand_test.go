@@ -0,0 +1,57 @@
+package easyssh
+
+import (
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParsingProxyCommand(t *testing.T) {
+	cfg := `
+Host internal
+	HostName 10.0.0.5
+	ProxyCommand ssh -W %h:%p bastion
+`
+	result, err := parseClientConfig(strings.NewReader(cfg), "internal")
+	if err != nil {
+		t.Fatalf("Error parsing config: %s", err)
+	}
+	if result.ProxyCommand != "ssh -W %h:%p bastion" {
+		t.Errorf("Expected whole ProxyCommand, got '%s'", result.ProxyCommand)
+	}
+
+	result.User = "john"
+	if command := result.expandProxyCommand(); command != "ssh -W 10.0.0.5:22 bastion" {
+		t.Errorf("Expected tokens to be expanded, got '%s'", command)
+	}
+
+	result.ProxyCommand = "connect %r@%h 100%%"
+	if command := result.expandProxyCommand(); command != "connect john@10.0.0.5 100%" {
+		t.Errorf("Expected tokens to be expanded, got '%s'", command)
+	}
+}
+
+func TestDialProxyCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs cat")
+	}
+
+	cfg := &MakeConfig{Server: "example.com", ProxyCommand: "cat"}
+	conn, err := cfg.dialDirect(cfg.address())
+	if err != nil {
+		t.Fatalf("Error starting ProxyCommand: %s", err)
+	}
+	defer conn.Close()
+
+	if _, err := io.WriteString(conn, "SSH-2.0-test\r\n"); err != nil {
+		t.Fatalf("Error writing: %s", err)
+	}
+	buf := make([]byte, 14)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "SSH-2.0-test\r\n" {
+		t.Errorf("Expected data to be echoed by cat, got %q (%v)", buf, err)
+	}
+	if conn.RemoteAddr().String() != "cat" {
+		t.Errorf("Expected command as address, got %s", conn.RemoteAddr())
+	}
+}
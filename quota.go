@@ -0,0 +1,128 @@
+package easyssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded is matched by the *QuotaError returned once a Quota is used
+// up.
+var ErrQuotaExceeded = errors.New("Quota exceeded")
+
+// Quota limits the work done using a MakeConfig, counted over all connections
+// made with it. Zero values mean no limit.
+type Quota struct {
+	// MaxBytes limits the bytes sent and received over the network.
+	MaxBytes int64
+	// MaxCommands limits the number of commands started.
+	MaxCommands int
+	// MaxLifetime limits the time since the first connection was made.
+	MaxLifetime time.Duration
+}
+
+// QuotaError is returned if a limit of the Quota of a MakeConfig is reached.
+// Limit is the name of the field of Quota (ex. "MaxCommands").
+type QuotaError struct {
+	Limit string
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrQuotaExceeded, e.Limit)
+}
+
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// quotaUsage holds the usage of the Quota of a MakeConfig.
+type quotaUsage struct {
+	mu       sync.Mutex
+	started  time.Time
+	bytes    int64
+	commands int
+}
+
+// returns the usage of the Quota of MakeConfig, starting the clock for
+// MaxLifetime on first use
+func (ssh_conf *MakeConfig) quotaUsage() *quotaUsage {
+	lazyInit.Lock()
+	defer lazyInit.Unlock()
+
+	if ssh_conf.usage == nil {
+		ssh_conf.usage = &quotaUsage{started: ssh_conf.clock().Now()}
+	}
+	return ssh_conf.usage
+}
+
+// checkQuota returns a *QuotaError if the Quota of MakeConfig is used up. If
+// command is set, a command is counted.
+func (ssh_conf *MakeConfig) checkQuota(command bool) error {
+	if ssh_conf.Quota == nil {
+		return nil
+	}
+	q := ssh_conf.Quota
+	usage := ssh_conf.quotaUsage()
+
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+
+	switch {
+	case q.MaxLifetime > 0 && ssh_conf.clock().Now().Sub(usage.started) > q.MaxLifetime:
+		return &QuotaError{Limit: "MaxLifetime"}
+	case q.MaxBytes > 0 && usage.bytes >= q.MaxBytes:
+		return &QuotaError{Limit: "MaxBytes"}
+	case command && q.MaxCommands > 0 && usage.commands >= q.MaxCommands:
+		return &QuotaError{Limit: "MaxCommands"}
+	}
+
+	if command {
+		usage.commands++
+	}
+	return nil
+}
+
+// countBytes wraps conn so that its traffic is counted against MaxBytes of the
+// Quota of MakeConfig. Once it is used up, reading and writing fail.
+func (ssh_conf *MakeConfig) countBytes(conn net.Conn) net.Conn {
+	if ssh_conf.Quota == nil || ssh_conf.Quota.MaxBytes <= 0 {
+		return conn
+	}
+	return &countingConn{Conn: conn, max: ssh_conf.Quota.MaxBytes, usage: ssh_conf.quotaUsage()}
+}
+
+type countingConn struct {
+	net.Conn
+	max   int64
+	usage *quotaUsage
+}
+
+// count adds n bytes to the usage and fails if the quota was used up before.
+func (c *countingConn) count(n int) error {
+	c.usage.mu.Lock()
+	defer c.usage.mu.Unlock()
+
+	if c.usage.bytes >= c.max {
+		return &QuotaError{Limit: "MaxBytes"}
+	}
+	c.usage.bytes += int64(n)
+	return nil
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	if err := c.count(0); err != nil {
+		return 0, err
+	}
+	n, err := c.Conn.Read(p)
+	c.count(n)
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	if err := c.count(len(p)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(p)
+}
@@ -0,0 +1,62 @@
+package easyssh
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestQuotaCommands(t *testing.T) {
+	cfg := &MakeConfig{Quota: &Quota{MaxCommands: 2}}
+
+	for i := 0; i < 2; i++ {
+		if err := cfg.checkQuota(true); err != nil {
+			t.Errorf("Expected command %d to be allowed, got %s", i+1, err)
+		}
+	}
+	if err := cfg.checkQuota(false); err != nil {
+		t.Errorf("Expected connecting to be allowed, got %s", err)
+	}
+
+	err := cfg.checkQuota(true)
+	var quotaErr *QuotaError
+	if !errors.Is(err, ErrQuotaExceeded) || !errors.As(err, &quotaErr) || quotaErr.Limit != "MaxCommands" {
+		t.Errorf("Expected MaxCommands to be exceeded, got %v", err)
+	}
+}
+
+func TestQuotaLifetime(t *testing.T) {
+	cfg := &MakeConfig{Quota: &Quota{MaxLifetime: time.Millisecond}}
+	if err := cfg.checkQuota(false); err != nil {
+		t.Errorf("Expected first use to be allowed, got %s", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if err := cfg.checkQuota(false); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected MaxLifetime to be exceeded, got %v", err)
+	}
+}
+
+func TestQuotaBytes(t *testing.T) {
+	cfg := &MakeConfig{Quota: &Quota{MaxBytes: 4}}
+	local, remote := net.Pipe()
+	defer remote.Close()
+	conn := cfg.countBytes(local)
+	defer conn.Close()
+
+	go remote.Read(make([]byte, 16))
+	if _, err := conn.Write([]byte("abcd")); err != nil {
+		t.Fatalf("Expected write within quota to succeed, got %s", err)
+	}
+	if _, err := conn.Write([]byte("e")); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected write to exceed quota, got %v", err)
+	}
+	if err := cfg.checkQuota(false); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected new connections to be refused, got %v", err)
+	}
+
+	if conn := (&MakeConfig{}).countBytes(local); conn != local {
+		t.Errorf("Expected connection not to be wrapped without quota")
+	}
+}
@@ -0,0 +1,105 @@
+package easyssh
+
+import (
+	"errors"
+	"sync"
+)
+
+// ReconnectOptions changes how StreamReconnect recovers from dropped
+// connections.
+type ReconnectOptions struct {
+	// Retry defines the delays between reconnects and how many attempts in a
+	// row are made (see RetryPolicy, Timeout is ignored). By default, it is
+	// retried forever, starting with a delay of one second. Receiving output
+	// resets the delay.
+	Retry *RetryPolicy
+	// OnReconnect is called with the number of the attempt and the error
+	// which interrupted the stream, before reconnecting.
+	OnReconnect func(attempt int, err error)
+	// Resume returns the command to run after reconnecting, given the number
+	// of lines received so far (ex. "tail -n +1001 -F app.log" to continue
+	// after 1000 lines). Without it, the command is run again from the start.
+	Resume func(received int) string
+}
+
+// ReconnectingStream is the output of a command run by StreamReconnect. Lines
+// receives the output of the command line by line. Errors receives the errors
+// which interrupted the stream, like a dropped connection; errors are dropped
+// if Errors is not read. Both channels are closed once the command exited,
+// reconnecting was given up or the stream is stopped.
+type ReconnectingStream struct {
+	Lines  <-chan string
+	Errors <-chan error
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// StreamReconnect runs command like Stream, but survives dropped connections:
+// if the session ends without an exit status, it reconnects with a backoff and
+// runs the command again, or the command returned by opts.Resume. The stream
+// ends once the command exits. If it fails, the *CommandError is sent to
+// Errors.
+func (ssh_conf *MakeConfig) StreamReconnect(command string, opts ReconnectOptions) *ReconnectingStream {
+	lines := make(chan string)
+	errs := make(chan error, 16)
+	s := &ReconnectingStream{Lines: lines, Errors: errs, stop: make(chan struct{})}
+
+	retry := opts.Retry
+	if retry == nil {
+		retry = &RetryPolicy{}
+	}
+
+	ssh_conf.spawn(func() {
+		defer close(errs)
+		defer close(lines)
+
+		received := 0
+		for attempt := 1; ; attempt++ {
+			n, err := ssh_conf.streamOnce(command, lines, s.stop)
+			received += n
+			if n > 0 {
+				attempt = 1
+			}
+
+			select {
+			case <-s.stop:
+				return
+			default:
+			}
+			if err == nil {
+				return
+			}
+
+			select {
+			case errs <- err:
+			default:
+			}
+			var cmdErr *CommandError
+			if errors.As(err, &cmdErr) || (retry.MaxAttempts > 0 && attempt >= retry.MaxAttempts) {
+				return
+			}
+
+			ssh_conf.logf(LogInfo, "Stream of '%s' on %s interrupted: %s", command, ssh_conf.address(), err)
+			select {
+			case <-s.stop:
+				return
+			case <-ssh_conf.clock().After(retry.jitter(retry.delay(attempt), ssh_conf.random())):
+			}
+
+			if opts.OnReconnect != nil {
+				opts.OnReconnect(attempt, err)
+			}
+			if opts.Resume != nil {
+				command = opts.Resume(received)
+			}
+		}
+	})
+
+	return s
+}
+
+// Stop ends the stream, closing the session, Lines and Errors.
+func (s *ReconnectingStream) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
@@ -0,0 +1,77 @@
+package easyssh
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
+)
+
+func TestStreamReconnect(t *testing.T) {
+	srv, err := testserver.New("test", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	// the first command blocks after two lines until the connection drops
+	srv.Handle("seq 5", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.WriteString(stdout, "1\n2\n")
+		ioutil.ReadAll(stdin)
+		return 0
+	})
+	srv.Handle("seq 3 5", testserver.Reply("3\n4\n5\n", 0))
+
+	var mu sync.Mutex
+	var conns []net.Conn
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cfg := &MakeConfig{User: "test", Password: "secret", Server: srv.Host(), Port: srv.Port(), Clock: clock,
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey()),
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := net.Dial(network, addr)
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+			return conn, err
+		}}
+
+	var reconnects []int
+	s := cfg.StreamReconnect("seq 5", ReconnectOptions{
+		OnReconnect: func(attempt int, err error) { reconnects = append(reconnects, attempt) },
+		Resume:      func(received int) string { return "seq 3 5" },
+	})
+	defer s.Stop()
+
+	var lines []string
+	for line := range s.Lines {
+		lines = append(lines, line)
+		if line == "2" {
+			mu.Lock()
+			conns[0].Close()
+			mu.Unlock()
+		}
+	}
+
+	if expected := []string{"1", "2", "3", "4", "5"}; !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected lines %v, got %v", expected, lines)
+	}
+	if !reflect.DeepEqual(reconnects, []int{1}) {
+		t.Errorf("Expected a single reconnect, got %v", reconnects)
+	}
+	if err := <-s.Errors; err == nil {
+		t.Errorf("Expected error which interrupted the stream")
+	}
+	if !reflect.DeepEqual(clock.waited, []time.Duration{time.Second}) {
+		t.Errorf("Expected to wait a second before reconnecting, got %v", clock.waited)
+	}
+	if commands := srv.Commands(); !reflect.DeepEqual(commands, []string{"seq 5", "seq 3 5"}) {
+		t.Errorf("Expected resumed command, got %v", commands)
+	}
+}
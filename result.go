@@ -0,0 +1,97 @@
+package easyssh
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Failed returns true if the operation could not be completed, a command
+// exited with a non-zero status or a workflow failed on the host.
+func (r *HostResult) Failed() bool {
+	return r.Err != nil || r.ExitStatus != 0
+}
+
+// reason describes why the operation failed on the host.
+func (r *HostResult) reason() string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+	return fmt.Sprintf("Process exited with status %d", r.ExitStatus)
+}
+
+// Results are the outcomes of an operation on the hosts of a Group.
+type Results []HostResult
+
+// Summary counts the results of an operation on multiple hosts. Duration is
+// the longest time an operation took on a single host.
+type Summary struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	Duration  time.Duration
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("%d hosts: %d succeeded, %d failed (%s)", s.Total, s.Succeeded, s.Failed, s.Duration)
+}
+
+// Failed returns the results of the hosts the operation failed on.
+func (results Results) Failed() Results {
+	var failed Results
+	for _, r := range results {
+		if r.Failed() {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// Succeeded returns the results of the hosts the operation succeeded on.
+func (results Results) Succeeded() Results {
+	var succeeded Results
+	for _, r := range results {
+		if !r.Failed() {
+			succeeded = append(succeeded, r)
+		}
+	}
+	return succeeded
+}
+
+// Hosts returns the hosts of the results.
+func (results Results) Hosts() []*MakeConfig {
+	hosts := make([]*MakeConfig, len(results))
+	for i, r := range results {
+		hosts[i] = r.Host
+	}
+	return hosts
+}
+
+// Summary counts the results.
+func (results Results) Summary() Summary {
+	s := Summary{Total: len(results)}
+	for _, r := range results {
+		if r.Failed() {
+			s.Failed++
+		} else {
+			s.Succeeded++
+		}
+		if r.Duration > s.Duration {
+			s.Duration = r.Duration
+		}
+	}
+	return s
+}
+
+// Err returns an error listing the hosts the operation failed on, or nil if it
+// succeeded on all of them.
+func (results Results) Err() error {
+	var msgs []string
+	for _, r := range results.Failed() {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", r.Host, r.reason()))
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("Failed on %d hosts: %s", len(msgs), strings.Join(msgs, "; "))
+}
@@ -0,0 +1,44 @@
+package easyssh
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResults(t *testing.T) {
+	web1 := &MakeConfig{User: "deploy", Server: "web1", Port: "22"}
+	web2 := &MakeConfig{User: "deploy", Server: "web2", Port: "22"}
+	db := &MakeConfig{User: "deploy", Server: "db", Port: "22"}
+
+	results := Results{
+		{Host: web1, Duration: time.Second},
+		{Host: web2, ExitStatus: 2, Duration: 3 * time.Second},
+		{Host: db, Err: errors.New("Connection refused"), Duration: 2 * time.Second},
+	}
+
+	if failed := results.Failed(); len(failed) != 2 || failed[0].Host != web2 || failed[1].Host != db {
+		t.Errorf("Expected web2 and db to have failed, got %v", failed.Hosts())
+	}
+	if succeeded := results.Succeeded(); len(succeeded) != 1 || succeeded[0].Host != web1 {
+		t.Errorf("Expected web1 to have succeeded, got %v", succeeded.Hosts())
+	}
+
+	expected := Summary{Total: 3, Succeeded: 1, Failed: 2, Duration: 3 * time.Second}
+	if summary := results.Summary(); summary != expected {
+		t.Errorf("Expected %v, got %v", expected, summary)
+	}
+	if expected, summary := "3 hosts: 1 succeeded, 2 failed (3s)", results.Summary().String(); summary != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, summary)
+	}
+
+	err := results.Err()
+	if err == nil || !strings.Contains(err.Error(), "deploy@web2:22: Process exited with status 2") ||
+		!strings.Contains(err.Error(), "deploy@db:22: Connection refused") {
+		t.Errorf("Expected error listing failed hosts, got %v", err)
+	}
+	if err := results.Succeeded().Err(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package easyssh
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy defines how often and how long connecting to a remote machine is
+// retried, which is handy to wait for the sshd of a freshly booted machine.
+// The delay between attempts starts with Backoff and is doubled after every
+// attempt, but never exceeds MaxBackoff. Zero values mean one second for
+// Backoff and no limit for MaxBackoff, MaxAttempts and Timeout. Jitter
+// randomizes every delay by up to the given fraction (ex. 0.2 for +/-20%), so
+// many clients don't retry in lockstep.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+	Jitter      float64
+}
+
+// delay returns how long to wait after the given (1-based) failed attempt.
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	if d <= 0 {
+		d = time.Second
+	}
+
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return d
+}
+
+// jitter randomizes d by up to Jitter using random, which returns numbers in
+// [0, 1).
+func (p *RetryPolicy) jitter(d time.Duration, random func() float64) time.Duration {
+	if p.Jitter <= 0 || random == nil {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + p.Jitter*(2*random()-1)))
+}
+
+// do calls f until it succeeds, returns an error retryable does not accept,
+// the maximum number of attempts is reached or ctx is done. The last error
+// returned by f is returned. Delays are measured by clock (the real one if nil)
+// and randomized using random.
+func (p *RetryPolicy) do(ctx context.Context, clock Clock, random func() float64, retryable func(error) bool, f func() error) error {
+	if clock == nil {
+		clock = realClock{}
+	}
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || !retryable(err) {
+			return err
+		}
+		if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-clock.After(p.jitter(p.delay(attempt), random)):
+		}
+	}
+}
+
+// isUnreachable reports whether err is a network error worth retrying.
+func isUnreachable(err error) bool {
+	return errors.Is(err, ErrHostUnreachable)
+}
+
+// isTransient reports whether err of a connection attempt may go away by
+// itself, like the host being unreachable or the connection being closed or
+// reset during the handshake by a sshd which is still starting up.
+func isTransient(err error) bool {
+	var netErr net.Error
+	return isUnreachable(err) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.As(err, &netErr)
+}
+
+// WaitForSSH tries to connect to the remote machine until it succeeds, ctx is
+// done or an error occurs which is not transient (see isTransient), like a
+// host key mismatch or an unreadable key. The Retry policy of MakeConfig is
+// used if set. Authentication failures are only retried if that policy limits
+// MaxAttempts or Timeout: freshly provisioned machines often start sshd before
+// the user's keys are installed, but wrong credentials would be retried
+// forever otherwise.
+func (ssh_conf *MakeConfig) WaitForSSH(ctx context.Context) error {
+	policy := ssh_conf.Retry
+	if policy == nil {
+		policy = &RetryPolicy{Backoff: time.Second, MaxBackoff: 10 * time.Second}
+	}
+
+	retryable := isTransient
+	if policy.MaxAttempts > 0 || policy.Timeout > 0 {
+		retryable = func(err error) bool {
+			return isTransient(err) || errors.Is(err, ErrAuthFailed)
+		}
+	}
+
+	return policy.do(ctx, ssh_conf.clock(), ssh_conf.random(), retryable, func() error {
+		client, err := ssh_conf.dialOnce(nil)
+		if err != nil {
+			return err
+		}
+		return client.Close()
+	})
+}
@@ -0,0 +1,121 @@
+package easyssh
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := &RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	expected := []time.Duration{100, 200, 400, 800, 1000, 1000}
+
+	for i, e := range expected {
+		if d := p.delay(i + 1); d != e*time.Millisecond {
+			t.Errorf("Expected delay %s after attempt %d, got %s", e*time.Millisecond, i+1, d)
+		}
+	}
+
+	if d := (&RetryPolicy{}).delay(1); d != time.Second {
+		t.Errorf("Expected default delay of 1s, got %s", d)
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	errTemporary := errors.New("temporary")
+	errPermanent := errors.New("permanent")
+	retryable := func(err error) bool { return err == errTemporary }
+
+	p := &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}
+	attempts := 0
+	err := p.do(context.Background(), nil, nil, retryable, func() error {
+		attempts++
+		return errTemporary
+	})
+	if err != errTemporary || attempts != 3 {
+		t.Errorf("Expected 3 failed attempts, got %d (%v)", attempts, err)
+	}
+
+	attempts = 0
+	err = p.do(context.Background(), nil, nil, retryable, func() error {
+		attempts++
+		if attempts == 2 {
+			return nil
+		}
+		return errTemporary
+	})
+	if err != nil || attempts != 2 {
+		t.Errorf("Expected success after 2 attempts, got %d (%v)", attempts, err)
+	}
+
+	attempts = 0
+	err = p.do(context.Background(), nil, nil, retryable, func() error {
+		attempts++
+		return errPermanent
+	})
+	if err != errPermanent || attempts != 1 {
+		t.Errorf("Expected no retry of permanent error, got %d attempts (%v)", attempts, err)
+	}
+
+	p = &RetryPolicy{Backoff: time.Hour, Timeout: 10 * time.Millisecond}
+	attempts = 0
+	err = p.do(context.Background(), nil, nil, retryable, func() error {
+		attempts++
+		return errTemporary
+	})
+	if err != errTemporary || attempts != 1 {
+		t.Errorf("Expected timeout to stop retrying, got %d attempts (%v)", attempts, err)
+	}
+}
+
+func TestWaitForSSHFailsFastOnConfigErrors(t *testing.T) {
+	for name, cfg := range map[string]*MakeConfig{
+		"bad key":        {KeyData: []byte("not a key"), InMemory: true},
+		"expired":        {Password: "secret", InMemory: true, CredentialsExpire: time.Unix(10, 0)},
+		"unknown method": {Password: "secret", InMemory: true, AuthMethods: []AuthMethod{"hostbased"}},
+	} {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		cfg.User, cfg.Server, cfg.Clock = "test", "localhost", clock
+		err := cfg.WaitForSSH(context.Background())
+		if err == nil || len(clock.waited) != 0 {
+			t.Errorf("Expected %s to fail on the first attempt, got %v after %d retries", name, err, len(clock.waited))
+		}
+		if name == "expired" && !errors.Is(err, ErrCredentialsExpired) {
+			t.Errorf("Expected ErrCredentialsExpired, got %v", err)
+		}
+	}
+}
+
+func TestWaitForSSHRetriesUnreachable(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	attempts := 0
+	cfg := &MakeConfig{User: "test", Server: "localhost", Password: "secret", InMemory: true, Clock: clock,
+		Retry: &RetryPolicy{MaxAttempts: 3},
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			attempts++
+			return nil, syscall.ECONNREFUSED
+		}}
+
+	if err := cfg.WaitForSSH(context.Background()); !errors.Is(err, ErrHostUnreachable) || attempts != 3 {
+		t.Errorf("Expected 3 attempts failing with ErrHostUnreachable, got %d (%v)", attempts, err)
+	}
+}
+
+func TestWaitForSSHAuthFailures(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cfg.Password, cfg.Clock = "wrong", clock
+
+	if err := cfg.WaitForSSH(context.Background()); !errors.Is(err, ErrAuthFailed) || len(clock.waited) != 0 {
+		t.Errorf("Expected ErrAuthFailed without retries by default, got %v after %d retries", err, len(clock.waited))
+	}
+
+	cfg.Retry = &RetryPolicy{MaxAttempts: 3}
+	if err := cfg.WaitForSSH(context.Background()); !errors.Is(err, ErrAuthFailed) || len(clock.waited) != 2 {
+		t.Errorf("Expected ErrAuthFailed after 3 attempts with a limited policy, got %v after %d retries", err, len(clock.waited))
+	}
+}
@@ -0,0 +1,138 @@
+package easyssh
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// SCPError is an error message reported by the remote scp process, such as
+// "scp: /etc/foo: Permission denied".
+type SCPError struct {
+	Message string
+	// Fatal is set if the remote scp process aborted the transfer. Otherwise
+	// the message is only a warning.
+	Fatal bool
+}
+
+func (e *SCPError) Error() string {
+	return e.Message
+}
+
+// scpReadResponse reads a single response of the remote scp process, which is
+// either a null byte for success or a warning (\x01) or fatal error (\x02)
+// followed by a message line.
+func scpReadResponse(r *bufio.Reader) error {
+	code, err := r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("Error reading scp response: %s", err)
+	}
+
+	switch code {
+	case 0:
+		return nil
+	case 1, 2:
+		msg, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("Error reading scp response: %s", err)
+		}
+		return &SCPError{Message: strings.TrimSpace(msg), Fatal: code == 2}
+	}
+
+	return fmt.Errorf("Unexpected scp response: %q", code)
+}
+
+// scpSendTimes sends a T record setting the modification and access time of
+// the next file transferred to a remote "scp -t" process.
+func scpSendTimes(w io.Writer, r *bufio.Reader, mtime, atime time.Time) error {
+	if _, err := fmt.Fprintf(w, "T%d 0 %d 0\n", mtime.Unix(), atime.Unix()); err != nil {
+		return err
+	}
+
+	return scpReadResponse(r)
+}
+
+// scpSendFile transfers a single file to a remote "scp -t" process using
+// the given C record mode (ex. "C0644"), waiting for the acknowledgement of
+// every step.
+func scpSendFile(w io.Writer, r *bufio.Reader, src io.Reader, mode string, size int64, name string) error {
+	if _, err := fmt.Fprintln(w, mode, size, name); err != nil {
+		return err
+	}
+	if err := scpReadResponse(r); err != nil {
+		return err
+	}
+
+	if _, err := io.CopyN(w, src, size); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(w, "\x00"); err != nil {
+		return err
+	}
+
+	return scpReadResponse(r)
+}
+
+// scpReceiveFile receives a single file from a remote "scp -f" process and
+// writes its content to dst. Time records sent by the remote side are
+// acknowledged, but ignored.
+func scpReceiveFile(w io.Writer, r *bufio.Reader, dst io.Writer) error {
+	ack := func() error {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+
+	// signal the source we are ready to receive
+	if err := ack(); err != nil {
+		return err
+	}
+
+	for {
+		code, err := r.ReadByte()
+		if err != nil {
+			return fmt.Errorf("Error reading scp record: %s", err)
+		}
+
+		switch code {
+		case 1, 2:
+			msg, _ := r.ReadString('\n')
+			return &SCPError{Message: strings.TrimSpace(msg), Fatal: code == 2}
+
+		case 'T':
+			if _, err := r.ReadString('\n'); err != nil {
+				return fmt.Errorf("Error reading scp time record: %s", err)
+			}
+			if err := ack(); err != nil {
+				return err
+			}
+
+		case 'C':
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("Error reading scp file record: %s", err)
+			}
+			var mode string
+			var size int64
+			if _, err := fmt.Sscanf(line, "%s %d", &mode, &size); err != nil {
+				return fmt.Errorf("Invalid scp file record: %q", "C"+line)
+			}
+			if err := ack(); err != nil {
+				return err
+			}
+			setTotal(dst, size)
+
+			if _, err := io.CopyN(dst, r, size); err != nil {
+				return err
+			}
+			if err := scpReadResponse(r); err != nil {
+				return err
+			}
+			return ack()
+
+		default:
+			return fmt.Errorf("Unexpected scp record: %q", code)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package easyssh
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
+)
+
+func TestSCPReadResponse(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("\x00\x01scp: warning\n\x02scp: /root/x: Permission denied\n\x03"))
+
+	if err := scpReadResponse(r); err != nil {
+		t.Errorf("Expected success, got %s", err)
+	}
+
+	err := scpReadResponse(r)
+	if scpErr, ok := err.(*SCPError); !ok || scpErr.Fatal || scpErr.Message != "scp: warning" {
+		t.Errorf("Expected warning, got %#v", err)
+	}
+
+	err = scpReadResponse(r)
+	if scpErr, ok := err.(*SCPError); !ok || !scpErr.Fatal || scpErr.Message != "scp: /root/x: Permission denied" {
+		t.Errorf("Expected fatal error, got %#v", err)
+	}
+
+	if err := scpReadResponse(r); err == nil {
+		t.Errorf("Expected error for unknown response code")
+	}
+}
+
+func TestSCPSendFile(t *testing.T) {
+	var out bytes.Buffer
+	acks := bufio.NewReader(strings.NewReader("\x00\x00"))
+
+	if err := scpSendFile(&out, acks, strings.NewReader("hello"), "C0644", 5, "greeting.txt"); err != nil {
+		t.Fatalf("Error sending file: %s", err)
+	}
+
+	if expected := "C0644 5 greeting.txt\nhello\x00"; out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+
+	acks = bufio.NewReader(strings.NewReader("\x02scp: /nonexistent: No such file or directory\n"))
+	err := scpSendFile(&out, acks, strings.NewReader("hello"), "C0644", 5, "greeting.txt")
+	if scpErr, ok := err.(*SCPError); !ok || scpErr.Message != "scp: /nonexistent: No such file or directory" {
+		t.Errorf("Expected remote error, got %#v", err)
+	}
+}
+
+func TestSCPSendTimes(t *testing.T) {
+	var out bytes.Buffer
+	acks := bufio.NewReader(strings.NewReader("\x00"))
+
+	mtime := time.Unix(1500000000, 0)
+	atime := time.Unix(1600000000, 0)
+	if err := scpSendTimes(&out, acks, mtime, atime); err != nil {
+		t.Fatalf("Error sending times: %s", err)
+	}
+
+	if expected := "T1500000000 0 1600000000 0\n"; out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestSCPReceiveFile(t *testing.T) {
+	var acks, content bytes.Buffer
+	input := bufio.NewReader(strings.NewReader("T1500000000 0 1500000000 0\nC0755 5 hello.sh\nhello\x00"))
+
+	if err := scpReceiveFile(&acks, input, &content); err != nil {
+		t.Fatalf("Error receiving file: %s", err)
+	}
+	if content.String() != "hello" {
+		t.Errorf("Expected content %q, got %q", "hello", content.String())
+	}
+	// ready, time record, file record, data
+	if expected := "\x00\x00\x00\x00"; acks.String() != expected {
+		t.Errorf("Expected acks %q, got %q", expected, acks.String())
+	}
+
+	input = bufio.NewReader(strings.NewReader("\x01scp: /etc/shadow: Permission denied\n"))
+	err := scpReceiveFile(&acks, input, &content)
+	if scpErr, ok := err.(*SCPError); !ok || scpErr.Message != "scp: /etc/shadow: Permission denied" {
+		t.Errorf("Expected remote error, got %#v", err)
+	}
+}
+
+func TestUploadQuotesRemotePath(t *testing.T) {
+	srv, err := testserver.New("test", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	cfg := &MakeConfig{User: "test", Password: "secret", Server: srv.Host(), Port: srv.Port(), InMemory: true,
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey())}
+
+	src, err := ioutil.TempFile("", "easyssh")
+	if err != nil {
+		t.Fatalf("Error creating temp file: %s", err)
+	}
+	defer os.Remove(src.Name())
+	src.WriteString("hello\n")
+	src.Close()
+
+	if err := cfg.Upload(src.Name(), "/my file;touch pwned"); err != nil {
+		t.Fatalf("Error uploading: %s", err)
+	}
+	if content, err := srv.ReadFile("/my file;touch pwned"); err != nil || string(content) != "hello\n" {
+		t.Errorf("Expected the file to be uploaded, got %q (%v)", content, err)
+	}
+	if commands := srv.Commands(); len(commands) != 1 || commands[0] != "scp -t '/my file;touch pwned'" {
+		t.Errorf("Expected the remote path to be quoted, got %v", commands)
+	}
+}
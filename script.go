@@ -0,0 +1,83 @@
+package easyssh
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// RunScript copies script to a temporary file on the remote machine, makes it
+// executable and runs it with args, which are quoted for the shell. Its stdout
+// and stderr are copied to the given writers as the data arrives, like RunTo
+// does. The script is removed afterwards. It needs to start with a shebang
+// line (ex. "#!/bin/sh") and the temporary directory must not be mounted
+// noexec.
+func (ssh_conf *MakeConfig) RunScript(script io.Reader, stdout, stderr io.Writer, args ...string) (exitStatus int, err error) {
+	client, err := ssh_conf.Connect()
+	if err != nil {
+		return -1, err
+	}
+	defer client.Close()
+
+	var tmpErr strings.Builder
+	var tmpOut strings.Builder
+	if status, err := client.RunTo("mktemp", &tmpOut, &tmpErr); err != nil || status != 0 {
+		if err == nil {
+			err = &CommandError{Command: "mktemp", ExitStatus: status}
+		}
+		return -1, remoteError([]byte(tmpErr.String()), err)
+	}
+	tmp := strings.TrimSpace(tmpOut.String())
+	if tmp == "" {
+		return -1, errors.New("Error creating temporary file: mktemp returned no name")
+	}
+	defer client.RunTo("rm -f -- "+shellQuote(tmp), nil, nil)
+
+	if err := client.writeFile(tmp, script, "chmod 700 "+shellQuote(tmp)); err != nil {
+		return -1, err
+	}
+
+	command := shellQuote(tmp)
+	for _, arg := range args {
+		command += " " + shellQuote(arg)
+	}
+	ssh_conf.logf(LogDebug1, "Running script on %s with %d arguments", ssh_conf.address(), len(args))
+
+	return client.RunTo(command, stdout, stderr)
+}
+
+// RunScriptFile works like RunScript, but reads the script from the local file
+// at path.
+func (ssh_conf *MakeConfig) RunScriptFile(path string, stdout, stderr io.Writer, args ...string) (exitStatus int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return -1, err
+	}
+	defer f.Close()
+
+	return ssh_conf.RunScript(f, stdout, stderr, args...)
+}
+
+// writeFile writes content to the file at path on the remote machine, running
+// then (ex. a chmod) afterwards if not empty.
+func (c *Client) writeFile(path string, content io.Reader, then string) error {
+	session, err := c.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	var stderr strings.Builder
+	session.Stdin = content
+	session.Stderr = &stderr
+
+	command := "cat > " + shellQuote(path)
+	if then != "" {
+		command += " && " + then
+	}
+	if err := c.config.run(session, command); err != nil {
+		return remoteFileError("write", path, []byte(stderr.String()), commandError(command, err))
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package easyssh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunScriptFileMissing(t *testing.T) {
+	var cfg MakeConfig
+	status, err := cfg.RunScriptFile(filepath.Join(t.TempDir(), "missing.sh"), nil, nil)
+	if !os.IsNotExist(err) || status != -1 {
+		t.Errorf("Expected missing script to be reported, got %d (%v)", status, err)
+	}
+}
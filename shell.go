@@ -0,0 +1,64 @@
+package easyssh
+
+import (
+	"os"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+// Shell opens an interactive shell on the remote machine and attaches it to the
+// local terminal, just like running ssh without a command. While the shell is
+// running the local terminal is put into raw mode and changes of the window
+// size are passed on to the remote side.
+func (ssh_conf *MakeConfig) Shell() error {
+	session, err := ssh_conf.connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	fd := int(os.Stdin.Fd())
+	width, height := 80, 24
+
+	if term.IsTerminal(fd) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer term.Restore(fd, state)
+
+		if w, h, err := term.GetSize(fd); err == nil {
+			width, height = w, h
+		}
+	}
+
+	termType := os.Getenv("TERM")
+	if termType == "" {
+		termType = "xterm"
+	}
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	if err := ssh_conf.request(session.Session, func() error {
+		return session.RequestPty(termType, height, width, modes)
+	}); err != nil {
+		return err
+	}
+
+	session.Stdin = os.Stdin
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+
+	if err := ssh_conf.request(session.Session, session.Shell); err != nil {
+		return err
+	}
+
+	stop := watchWindowSize(fd, session.Session, ssh_conf.spawn)
+	defer stop()
+
+	return ssh_conf.wait(session.Session)
+}
@@ -0,0 +1,62 @@
+package easyssh
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// replaceStdio points os.Stdin to a file containing input and os.Stdout to an
+// empty file, which are no terminals. The returned function restores both and
+// returns what was written to stdout.
+func replaceStdio(t *testing.T, input string) (restore func() string) {
+	stdin, err := ioutil.TempFile("", "easyssh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin.WriteString(input)
+	stdin.Seek(0, io.SeekStart)
+	stdout, err := ioutil.TempFile("", "easyssh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldStdin, oldStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdin, stdout
+	return func() string {
+		os.Stdin, os.Stdout = oldStdin, oldStdout
+		stdin.Close()
+		stdout.Close()
+		defer os.Remove(stdin.Name())
+		defer os.Remove(stdout.Name())
+		output, _ := ioutil.ReadFile(stdout.Name())
+		return string(output)
+	}
+}
+
+func TestShellWithoutTerminal(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	srv.HandleShell(func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		input, _ := ioutil.ReadAll(stdin)
+		io.WriteString(stdout, strings.ToUpper(string(input)))
+		return 3
+	})
+
+	restore := replaceStdio(t, "uptime\nexit\n")
+	err := cfg.Shell()
+	output := restore()
+
+	if output != "UPTIME\nEXIT\n" {
+		t.Errorf("Expected stdin to be passed through to stdout, got %q", output)
+	}
+	var exitErr *ssh.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 3 {
+		t.Errorf("Expected exit status 3, got %v", err)
+	}
+}
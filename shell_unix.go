@@ -0,0 +1,38 @@
+//go:build !windows
+
+package easyssh
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+// watchWindowSize passes size changes of the local terminal on to the remote
+// pty until the returned function is called.
+func watchWindowSize(fd int, session *ssh.Session, spawn func(func())) (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGWINCH)
+	done := make(chan struct{})
+
+	spawn(func() {
+		for {
+			select {
+			case <-sigs:
+				if w, h, err := term.GetSize(fd); err == nil {
+					session.WindowChange(h, w)
+				}
+			case <-done:
+				return
+			}
+		}
+	})
+
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
@@ -0,0 +1,8 @@
+package easyssh
+
+import "golang.org/x/crypto/ssh"
+
+// watchWindowSize is a no-op on Windows, which has no SIGWINCH.
+func watchWindowSize(fd int, session *ssh.Session, spawn func(func())) (stop func()) {
+	return func() {}
+}
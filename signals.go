@@ -0,0 +1,84 @@
+package easyssh
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// signalForwarder passes SIGINT and SIGTERM received by the local process on to
+// all remote commands started with ForwardSignals set.
+type signalForwarder struct {
+	mu       sync.Mutex
+	sessions map[*ssh.Session]bool
+	sigs     chan os.Signal
+}
+
+var forwarder signalForwarder
+
+// maps local signals to their SSH counterparts
+func sshSignal(sig os.Signal) ssh.Signal {
+	if sig == syscall.SIGTERM {
+		return ssh.SIGTERM
+	}
+	return ssh.SIGINT
+}
+
+// add starts forwarding signals to session. The signal handler is installed
+// when the first session is added.
+func (f *signalForwarder) add(session *ssh.Session, spawn func(func())) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.sessions == nil {
+		f.sessions = map[*ssh.Session]bool{}
+	}
+	f.sessions[session] = true
+
+	if f.sigs != nil {
+		return
+	}
+	sigs := make(chan os.Signal, 1)
+	f.sigs = sigs
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	spawn(func() {
+		for sig := range sigs {
+			f.mu.Lock()
+			for session := range f.sessions {
+				session.Signal(sshSignal(sig))
+			}
+			f.mu.Unlock()
+		}
+	})
+}
+
+// remove stops forwarding signals to session. The signal handler is removed
+// again together with the last session.
+func (f *signalForwarder) remove(session *ssh.Session) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.sessions, session)
+	if len(f.sessions) == 0 && f.sigs != nil {
+		signal.Stop(f.sigs)
+		close(f.sigs)
+		f.sigs = nil
+	}
+}
+
+// forwardSignals passes SIGINT and SIGTERM on to the command running in
+// session until the returned function is called, if ForwardSignals is set.
+func (ssh_conf *MakeConfig) forwardSignals(session *ssh.Session) (stop func()) {
+	if !ssh_conf.ForwardSignals {
+		return func() {}
+	}
+
+	forwarder.add(session, ssh_conf.spawn)
+	return func() {
+		forwarder.remove(session)
+	}
+}
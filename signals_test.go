@@ -0,0 +1,36 @@
+package easyssh
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSignalForwarderLifecycle(t *testing.T) {
+	cfg := &MakeConfig{ForwardSignals: true}
+	a, b := &ssh.Session{}, &ssh.Session{}
+
+	stopA := cfg.forwardSignals(a)
+	stopB := cfg.forwardSignals(b)
+	if forwarder.sigs == nil || len(forwarder.sessions) != 2 {
+		t.Errorf("Expected signal handler for 2 sessions to be installed")
+	}
+
+	stopA()
+	if forwarder.sigs == nil {
+		t.Errorf("Expected signal handler to stay installed while sessions are running")
+	}
+
+	stopB()
+	if forwarder.sigs != nil || len(forwarder.sessions) != 0 {
+		t.Errorf("Expected signal handler to be removed with the last session")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := cfg.WaitIdle(ctx); err != nil {
+		t.Errorf("Expected forwarding goroutine to exit, got %s", err)
+	}
+}
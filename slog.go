@@ -0,0 +1,56 @@
+package easyssh
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+// labelLogger is implemented by Loggers which record the Labels of MakeConfig
+// as structured attributes instead of as part of the message.
+type labelLogger interface {
+	logLabels(level LogLevel, msg string, labels map[string]string)
+}
+
+// SlogLogger returns a Logger passing the log messages of easyssh to l. The
+// levels of easyssh are mapped to slog levels: LogError and LogFatal to
+// slog.LevelError, LogInfo to slog.LevelInfo, LogVerbose to slog.LevelDebug
+// and the debug levels below that. The Labels of MakeConfig are added as
+// attributes.
+func SlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) Log(level LogLevel, msg string) {
+	l.logLabels(level, msg, nil)
+}
+
+func (l slogLogger) logLabels(level LogLevel, msg string, labels map[string]string) {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, len(keys))
+	for i, key := range keys {
+		attrs[i] = slog.String(key, labels[key])
+	}
+
+	l.logger.LogAttrs(context.Background(), slogLevel(level), msg, attrs...)
+}
+
+// slogLevel maps level to the corresponding slog.Level.
+func slogLevel(level LogLevel) slog.Level {
+	switch {
+	case level <= LogError:
+		return slog.LevelError
+	case level == LogInfo:
+		return slog.LevelInfo
+	}
+	return slog.LevelDebug - slog.Level(level-LogVerbose)
+}
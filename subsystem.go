@@ -0,0 +1,83 @@
+package easyssh
+
+import (
+	"io"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Subsystem is an SSH subsystem (ex. "netconf" or a vendor specific one)
+// running on the remote machine. Its protocol is spoken by writing to and
+// reading from it. Output on stderr is discarded.
+type Subsystem struct {
+	session io.Closer
+	stdin   io.WriteCloser
+	stdout  io.Reader
+}
+
+// Subsystem connects to the remote machine and starts the subsystem name.
+// Closing the subsystem closes the connection.
+func (ssh_conf *MakeConfig) Subsystem(name string) (*Subsystem, error) {
+	session, err := ssh_conf.connect()
+	if err != nil {
+		return nil, err
+	}
+	return ssh_conf.startSubsystem(session.Session, session, name)
+}
+
+// Subsystem starts the subsystem name in a new session on the connection.
+func (c *Client) Subsystem(name string) (*Subsystem, error) {
+	session, err := c.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	return c.config.startSubsystem(session, session, name)
+}
+
+// startSubsystem starts the subsystem name in session. closer closes the
+// session (and the connection, if it is one of its own) once the subsystem is
+// closed or starting it fails.
+func (ssh_conf *MakeConfig) startSubsystem(session *ssh.Session, closer io.Closer, name string) (*Subsystem, error) {
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		closer.Close()
+		return nil, err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		closer.Close()
+		return nil, err
+	}
+
+	if err := ssh_conf.request(session, func() error {
+		return session.RequestSubsystem(name)
+	}); err != nil {
+		closer.Close()
+		ssh_conf.logf(LogError, "Error starting subsystem %s on %s: %s", name, ssh_conf.address(), err)
+		return nil, err
+	}
+	ssh_conf.logf(LogDebug1, "Started subsystem %s on %s", name, ssh_conf.address())
+
+	return &Subsystem{session: closer, stdin: stdin, stdout: stdout}, nil
+}
+
+// Read reads output of the subsystem.
+func (s *Subsystem) Read(p []byte) (int, error) {
+	return s.stdout.Read(p)
+}
+
+// Write sends input to the subsystem.
+func (s *Subsystem) Write(p []byte) (int, error) {
+	return s.stdin.Write(p)
+}
+
+// CloseWrite signals the end of the input to the subsystem.
+func (s *Subsystem) CloseWrite() error {
+	return s.stdin.Close()
+}
+
+// Close closes the session of the subsystem.
+func (s *Subsystem) Close() error {
+	s.stdin.Close()
+	return s.session.Close()
+}
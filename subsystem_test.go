@@ -0,0 +1,45 @@
+package easyssh
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestClientSubsystem(t *testing.T) {
+	srv, client := connectTestServer(t)
+	defer srv.Close()
+	defer client.Close()
+
+	srv.HandleSubsystem("upper", func(name string, stdin io.Reader, stdout, stderr io.Writer) int {
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			io.WriteString(stdout, strings.ToUpper(scanner.Text())+"\n")
+		}
+		return 0
+	})
+
+	if _, err := client.Subsystem("missing"); err == nil {
+		t.Errorf("Expected unknown subsystem to be rejected")
+	}
+
+	s, err := client.Subsystem("upper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	io.WriteString(s, "hello\n")
+	reader := bufio.NewReader(s)
+	line, err := reader.ReadString('\n')
+	if err != nil || line != "HELLO\n" {
+		t.Errorf("Expected reply of subsystem, got %q (%v)", line, err)
+	}
+
+	s.CloseWrite()
+	if rest, err := ioutil.ReadAll(reader); err != nil || len(rest) != 0 {
+		t.Errorf("Expected subsystem to end its output, got %q (%v)", rest, err)
+	}
+}
@@ -0,0 +1,27 @@
+package easyssh
+
+import "strings"
+
+// RunSudo runs command as root on the remote machine using sudo and returns its
+// stdout as a string. The password is passed to sudo on stdin, so no pty is
+// needed and it never shows up in the process list or the output. If the
+// command fails, the returned error is a *CommandError holding its exit
+// status. Note that with passwordless sudo the password will be passed on to
+// the command's stdin instead. If MakeConfig has an Escalation, its method,
+// user and flags are used with sudoPassword.
+func (ssh_conf *MakeConfig) RunSudo(command, sudoPassword string) (outStr string, err error) {
+	e := ssh_conf.escalation()
+	e.Password = sudoPassword
+
+	stdout, _, err := ssh_conf.become(e, command)
+	return string(stdout), err
+}
+
+// isSudoAuthFailure checks whether sudo's stderr output indicates that the
+// given password was not accepted.
+func isSudoAuthFailure(stderr []byte) bool {
+	msg := string(stderr)
+	return strings.Contains(msg, "incorrect password") ||
+		strings.Contains(msg, "Sorry, try again") ||
+		strings.Contains(msg, "no password was provided")
+}
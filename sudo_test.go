@@ -0,0 +1,10 @@
+package easyssh
+
+import "testing"
+
+func TestSudoCommand(t *testing.T) {
+	expected := `sudo -S -k -p '' -- sh -c 'echo '\''hi'\'' > /root/x'`
+	if result, _ := (Escalation{}).command("echo 'hi' > /root/x", true); result != expected {
+		t.Errorf("Expected %s, got %s", expected, result)
+	}
+}
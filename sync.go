@@ -0,0 +1,228 @@
+package easyssh
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+// SyncOptions changes how Sync compares and updates files.
+type SyncOptions struct {
+	// Checksum compares files by their SHA-256 instead of their size and
+	// modification time. This needs sha256sum on the remote machine.
+	Checksum bool
+	// Delete removes remote files which do not exist locally.
+	Delete bool
+	// DryRun only reports what would be changed.
+	DryRun bool
+}
+
+// SyncResult lists the files, relative to the synchronized directories, which
+// were uploaded to or deleted from the remote machine.
+type SyncResult struct {
+	Uploaded []string
+	Deleted  []string
+}
+
+// remoteFileInfo is what Sync knows about a remote file.
+type remoteFileInfo struct {
+	size    int64
+	modTime int64
+	sum     string
+}
+
+// Sync makes remoteDir on the remote machine match localDir like rsync does:
+// Only regular files which are missing or differ (by size and modification
+// time, or by checksum, see SyncOptions) are uploaded, all in a single tar
+// stream preserving their modification times. Empty directories and symlinks
+// are not synchronized.
+func (ssh_conf *MakeConfig) Sync(localDir, remoteDir string, opts SyncOptions) (*SyncResult, error) {
+	remote, err := ssh_conf.listRemoteFiles(remoteDir, opts.Checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{}
+	local := map[string]bool{}
+	err = filepath.Walk(localDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		name, err := filepath.Rel(localDir, file)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+		local[name] = true
+
+		changed, err := fileChanged(file, info, remote[name], opts.Checksum)
+		if err != nil {
+			return err
+		}
+		if changed {
+			result.Uploaded = append(result.Uploaded, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range remote {
+		if opts.Delete && !local[name] {
+			result.Deleted = append(result.Deleted, name)
+		}
+	}
+	sort.Strings(result.Deleted)
+
+	ssh_conf.logf(LogDebug1, "Syncing %s to %s:%s: %d files to upload, %d to delete",
+		localDir, ssh_conf.address(), remoteDir, len(result.Uploaded), len(result.Deleted))
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if len(result.Uploaded) > 0 {
+		upload := map[string]bool{}
+		for _, name := range result.Uploaded {
+			upload[name] = true
+		}
+		err := ssh_conf.uploadTar(localDir, remoteDir, func(name string, info os.FileInfo) bool {
+			return upload[name]
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(result.Deleted) > 0 {
+		command := "cd -- " + shellQuote(remoteDir) + " && rm -f --"
+		for _, name := range result.Deleted {
+			command += " " + shellQuote(name)
+		}
+		if _, stderr, err := ssh_conf.capture(command); err != nil {
+			return nil, remoteFileError("delete", remoteDir, stderr, err)
+		}
+	}
+
+	return result, nil
+}
+
+// fileChanged checks whether the local file differs from the remote one, which
+// is nil if it does not exist.
+func fileChanged(file string, info os.FileInfo, remote *remoteFileInfo, checksum bool) (bool, error) {
+	if remote == nil || remote.size != info.Size() {
+		return true, nil
+	}
+	if !checksum {
+		return remote.modTime != info.ModTime().Unix(), nil
+	}
+
+	sum, err := localSHA256(file)
+	if err != nil {
+		return false, err
+	}
+	return sum != remote.sum, nil
+}
+
+// listRemoteFiles returns the regular files below dir by their path relative
+// to dir. A missing dir is returned as empty. The files are listed using SFTP
+// if available, otherwise using find and stat.
+func (ssh_conf *MakeConfig) listRemoteFiles(dir string, checksum bool) (map[string]*remoteFileInfo, error) {
+	files, err := ssh_conf.listRemoteFilesSFTP(dir)
+	if err == errUnavailable {
+		ssh_conf.logf(LogDebug1, "SFTP not available on %s, listing %s using find", ssh_conf.address(), dir)
+		files, err = ssh_conf.listRemoteFilesFind(dir)
+	}
+	if err != nil || !checksum || len(files) == 0 {
+		return files, err
+	}
+
+	stdout, stderr, err := ssh_conf.capture("cd -- " + shellQuote(dir) + " && find . -type f -exec sha256sum -- {} +")
+	if err != nil {
+		return nil, remoteFileError("checksum", dir, stderr, err)
+	}
+	for _, line := range strings.Split(string(stdout), "\n") {
+		fields := strings.SplitN(line, "  ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		if f := files[strings.TrimPrefix(fields[1], "./")]; f != nil {
+			f.sum = fields[0]
+		}
+	}
+
+	return files, nil
+}
+
+func (ssh_conf *MakeConfig) listRemoteFilesSFTP(dir string) (map[string]*remoteFileInfo, error) {
+	client, err := ssh_conf.Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	sftpClient, err := sftp.NewClient(client.client)
+	if err != nil {
+		return nil, errUnavailable
+	}
+	defer sftpClient.Close()
+
+	files := map[string]*remoteFileInfo{}
+	walker := sftpClient.Walk(dir)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			if os.IsNotExist(err) && walker.Path() == dir {
+				return files, nil
+			}
+			return nil, err
+		}
+		info := walker.Stat()
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		name := strings.TrimPrefix(walker.Path(), path.Clean(dir)+"/")
+		files[name] = &remoteFileInfo{size: info.Size(), modTime: info.ModTime().Unix()}
+	}
+
+	return files, nil
+}
+
+func (ssh_conf *MakeConfig) listRemoteFilesFind(dir string) (map[string]*remoteFileInfo, error) {
+	d := shellQuote(dir)
+	command := "[ -d " + d + " ] || exit 0; cd -- " + d + " && find . -type f -exec stat -c '%s %Y %n' {} +"
+	stdout, stderr, err := ssh_conf.capture(command)
+	if err != nil {
+		return nil, remoteFileError("list", dir, stderr, err)
+	}
+	return parseStatListing(string(stdout))
+}
+
+// parses lines of "size mtime ./name" as printed by stat -c '%s %Y %n'
+func parseStatListing(output string) (map[string]*remoteFileInfo, error) {
+	files := map[string]*remoteFileInfo{}
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("Unexpected output of stat: %q", line)
+		}
+		size, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Unexpected output of stat: %q", line)
+		}
+		modTime, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Unexpected output of stat: %q", line)
+		}
+		files[strings.TrimPrefix(fields[2], "./")] = &remoteFileInfo{size: size, modTime: modTime}
+	}
+	return files, nil
+}
@@ -0,0 +1,56 @@
+package easyssh
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseStatListing(t *testing.T) {
+	files, err := parseStatListing("12 1700000000 ./a.txt\n0 1700000001 ./dir/with space\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f := files["a.txt"]; f == nil || f.size != 12 || f.modTime != 1700000000 {
+		t.Errorf("Expected a.txt with 12 bytes, got %+v", f)
+	}
+	if f := files["dir/with space"]; f == nil || f.size != 0 {
+		t.Errorf("Expected file name with space, got %+v", files)
+	}
+
+	if _, err := parseStatListing("garbage\n"); err == nil {
+		t.Errorf("Expected error for unexpected output")
+	}
+}
+
+func TestFileChanged(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.txt")
+	ioutil.WriteFile(file, []byte("hello"), 0644)
+	mtime := time.Unix(1700000000, 0)
+	os.Chtimes(file, mtime, mtime)
+	info, _ := os.Stat(file)
+
+	sum, _ := localSHA256(file)
+	same := &remoteFileInfo{size: 5, modTime: 1700000000, sum: sum}
+
+	for _, tc := range []struct {
+		remote   *remoteFileInfo
+		checksum bool
+		expected bool
+	}{
+		{nil, false, true},
+		{same, false, false},
+		{&remoteFileInfo{size: 4, modTime: 1700000000}, false, true},
+		{&remoteFileInfo{size: 5, modTime: 1600000000}, false, true},
+		{&remoteFileInfo{size: 5, modTime: 1600000000, sum: sum}, true, false},
+		{&remoteFileInfo{size: 5, modTime: 1700000000, sum: "00"}, true, true},
+	} {
+		changed, err := fileChanged(file, info, tc.remote, tc.checksum)
+		if err != nil || changed != tc.expected {
+			t.Errorf("Expected changed=%v for %+v, got %v (%v)", tc.expected, tc.remote, changed, err)
+		}
+	}
+}
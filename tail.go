@@ -0,0 +1,130 @@
+package easyssh
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// tailReconnectDelay is the time waited before reconnecting a Tail.
+const tailReconnectDelay = time.Second
+
+// Tail follows a remote file, see TailFile. Lines receives the lines appended
+// to the file. Errors receives the errors which interrupted following the
+// file, like a dropped connection; errors are dropped if Errors is not read.
+// Both channels are closed once the Tail is stopped or failed permanently.
+type Tail struct {
+	Lines  <-chan string
+	Errors <-chan error
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// TailFile follows the file at path on the remote machine like tail -F does,
+// starting at its current end. If the connection drops, it reconnects
+// transparently and continues with the lines appended from then on, so lines
+// written while disconnected are lost. Tailing stops once Stop is called or
+// tail itself fails (ex. because the file cannot be read).
+func (ssh_conf *MakeConfig) TailFile(path string) *Tail {
+	lines := make(chan string)
+	errs := make(chan error, 16)
+	t := &Tail{Lines: lines, Errors: errs, stop: make(chan struct{})}
+
+	ssh_conf.spawn(func() {
+		defer close(errs)
+		defer close(lines)
+
+		for {
+			err := ssh_conf.tailOnce(path, lines, t.stop)
+			select {
+			case <-t.stop:
+				return
+			default:
+			}
+
+			ssh_conf.logf(LogInfo, "Tailing %s:%s interrupted: %s", ssh_conf.address(), path, err)
+			select {
+			case errs <- err:
+			default:
+			}
+			var cmdErr *CommandError
+			if errors.As(err, &cmdErr) {
+				return
+			}
+
+			select {
+			case <-t.stop:
+				return
+			case <-ssh_conf.clock().After(tailReconnectDelay):
+			}
+		}
+	})
+
+	return t
+}
+
+// Stop stops following the file and closes Lines and Errors.
+func (t *Tail) Stop() {
+	t.stopOnce.Do(func() { close(t.stop) })
+}
+
+// tailOnce runs tail on a single session, sending the lines read to lines until
+// the session ends or stop is closed.
+func (ssh_conf *MakeConfig) tailOnce(path string, lines chan<- string, stop <-chan struct{}) error {
+	_, err := ssh_conf.streamOnce("tail -n0 -F -- "+shellQuote(path), lines, stop)
+	if err == nil {
+		select {
+		case <-stop:
+		default:
+			err = errors.New("Tail exited unexpectedly")
+		}
+	}
+	return err
+}
+
+// streamOnce runs command on a single session, sending the lines of its
+// output to lines until the session ends or stop is closed. It returns the
+// number of lines sent and a *CommandError if command failed.
+func (ssh_conf *MakeConfig) streamOnce(command string, lines chan<- string, stop <-chan struct{}) (int, error) {
+	session, err := ssh_conf.connect()
+	if err != nil {
+		return 0, err
+	}
+	defer session.Close()
+
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+
+	finished := make(chan struct{})
+	defer close(finished)
+	ssh_conf.spawn(func() {
+		select {
+		case <-stop:
+			session.Close()
+		case <-finished:
+		}
+	})
+
+	if err := ssh_conf.start(session.Session, command); err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	scanner := ssh_conf.scanner(r)
+	for scanner.Scan() {
+		select {
+		case lines <- scanner.Text():
+			sent++
+		case <-stop:
+			return sent, nil
+		}
+	}
+
+	if err := ssh_conf.wait(session.Session); err != nil {
+		return sent, commandError(command, err)
+	}
+	return sent, scanner.Err()
+}
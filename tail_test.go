@@ -0,0 +1,40 @@
+package easyssh
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestTailFileReportsErrorsAndStops(t *testing.T) {
+	dialErr := errors.New("network down")
+	cfg := &MakeConfig{Server: "example.com", Port: "22", User: "test", Password: "secret",
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, dialErr
+		}}
+
+	tail := cfg.TailFile("/var/log/syslog")
+
+	select {
+	case err := <-tail.Errors:
+		if err == nil {
+			t.Errorf("Expected connection error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected error to be reported")
+	}
+
+	tail.Stop()
+	tail.Stop()
+
+	select {
+	case _, ok := <-tail.Lines:
+		if ok {
+			t.Errorf("Expected no lines")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Lines to be closed after Stop")
+	}
+}
@@ -0,0 +1,233 @@
+package easyssh
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// UploadDir copies the directory tree localDir to remoteDir on the remote
+// machine, which is created if missing. The tree is streamed as a tar archive
+// into tar on the remote side over a single session, which is much faster than
+// copying many small files one by one.
+func (ssh_conf *MakeConfig) UploadDir(localDir, remoteDir string) error {
+	return ssh_conf.uploadTar(localDir, remoteDir, nil)
+}
+
+// uploadTar streams the entries of localDir selected by include (see writeTar)
+// into tar running in remoteDir.
+func (ssh_conf *MakeConfig) uploadTar(localDir, remoteDir string, include func(string, os.FileInfo) bool) error {
+	session, err := ssh_conf.connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	w, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	session.Stderr = &stderr
+
+	dir := shellQuote(remoteDir)
+	if err := ssh_conf.start(session.Session, "mkdir -p -- "+dir+" && tar -xf - -C "+dir); err != nil {
+		return err
+	}
+
+	ssh_conf.logf(LogDebug1, "Uploading %s to %s:%s using tar", localDir, ssh_conf.address(), remoteDir)
+	counter := &byteCounter{w: w}
+	writeErr := writeTar(counter, localDir, include)
+	w.Close()
+
+	defer ssh_conf.forwardSignals(session.Session)()
+	if err := ssh_conf.wait(session.Session); err != nil {
+		err = remoteFileError("upload", remoteDir, []byte(stderr.String()), commandError("tar -x", err))
+		ssh_conf.logf(LogError, "Error uploading %s to %s:%s: %s", localDir, ssh_conf.address(), remoteDir, err)
+		return err
+	}
+	ssh_conf.bytesTransferred("upload", counter.n)
+
+	return writeErr
+}
+
+// DownloadDir copies the directory tree remoteDir from the remote machine to
+// localDir, which is created if missing. Like UploadDir, the tree is streamed
+// as a single tar archive.
+func (ssh_conf *MakeConfig) DownloadDir(remoteDir, localDir string) error {
+	session, err := ssh_conf.connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	session.Stderr = &stderr
+
+	if err := ssh_conf.start(session.Session, "tar -cf - -C "+shellQuote(remoteDir)+" ."); err != nil {
+		return err
+	}
+
+	ssh_conf.logf(LogDebug1, "Downloading %s:%s to %s using tar", ssh_conf.address(), remoteDir, localDir)
+	counter := &byteCounter{r: r}
+	readErr := readTar(counter, localDir)
+	if readErr != nil {
+		// drain the archive, so tar on the remote side does not block
+		io.Copy(ioutil.Discard, r)
+	}
+
+	defer ssh_conf.forwardSignals(session.Session)()
+	if err := ssh_conf.wait(session.Session); err != nil {
+		err = remoteFileError("download", remoteDir, []byte(stderr.String()), commandError("tar -c", err))
+		ssh_conf.logf(LogError, "Error downloading %s:%s to %s: %s", ssh_conf.address(), remoteDir, localDir, err)
+		return err
+	}
+	ssh_conf.bytesTransferred("download", counter.n)
+
+	return readErr
+}
+
+// writeTar writes the tree below dir to w as tar archive with paths relative to
+// dir. If include is not nil, only the entries it returns true for are written.
+func writeTar(w io.Writer, dir string, include func(name string, info os.FileInfo) bool) error {
+	tw := tar.NewWriter(w)
+
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, file)
+		if err != nil || name == "." {
+			return err
+		}
+		if include != nil && !include(filepath.ToSlash(name), info) {
+			return nil
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		// the owner is not preserved, files belong to the user logged in
+		header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+// readTar extracts the tar archive read from r into dir. Entries pointing
+// outside of dir, directly or through a symlink of the archive, are rejected.
+func readTar(r io.Reader, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	type dirTimes struct {
+		path    string
+		modTime time.Time
+	}
+	var dirs []dirTimes
+	symlinks := map[string]bool{}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(strings.TrimSuffix(header.Name, "/"))
+		target := filepath.Join(dir, name)
+		if name == "." || name == "" {
+			continue
+		}
+		if strings.HasPrefix(header.Name, "/") || !strings.HasPrefix(target, filepath.Clean(dir)+string(filepath.Separator)) {
+			return fmt.Errorf("Invalid path in tar archive: %s", header.Name)
+		}
+		for parent := filepath.Dir(name); parent != "."; parent = filepath.Dir(parent) {
+			if symlinks[parent] {
+				return fmt.Errorf("Invalid path in tar archive: %s", header.Name)
+			}
+		}
+
+		mode := os.FileMode(header.Mode).Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode|0700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirTimes{target, header.ModTime})
+			continue
+		case tar.TypeReg:
+			f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			os.Remove(target)
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+			symlinks[name] = true
+			continue
+		default:
+			continue
+		}
+
+		os.Chtimes(target, header.ModTime, header.ModTime)
+	}
+
+	// set the times of directories last, as creating files inside changes them
+	for _, d := range dirs {
+		os.Chtimes(d.path, d.modTime, d.modTime)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package easyssh
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestTarRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "a", "b"), 0755)
+	ioutil.WriteFile(filepath.Join(src, "top.txt"), []byte("top"), 0644)
+	ioutil.WriteFile(filepath.Join(src, "a", "b", "run.sh"), []byte("#!/bin/sh\n"), 0755)
+	if runtime.GOOS != "windows" {
+		os.Symlink("top.txt", filepath.Join(src, "link"))
+	}
+
+	var buf bytes.Buffer
+	if err := writeTar(&buf, src, nil); err != nil {
+		t.Fatalf("Error writing archive: %s", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "copy")
+	if err := readTar(&buf, dst); err != nil {
+		t.Fatalf("Error reading archive: %s", err)
+	}
+
+	if data, err := ioutil.ReadFile(filepath.Join(dst, "top.txt")); err != nil || string(data) != "top" {
+		t.Errorf("Expected top.txt to be copied, got %q (%v)", data, err)
+	}
+	info, err := os.Stat(filepath.Join(dst, "a", "b", "run.sh"))
+	if err != nil {
+		t.Fatalf("Expected nested file to be copied: %s", err)
+	}
+	if runtime.GOOS != "windows" {
+		if info.Mode().Perm() != 0755 {
+			t.Errorf("Expected mode 0755, got %o", info.Mode().Perm())
+		}
+		if link, err := os.Readlink(filepath.Join(dst, "link")); err != nil || link != "top.txt" {
+			t.Errorf("Expected symlink to top.txt, got %q (%v)", link, err)
+		}
+	}
+}
+
+func TestReadTarRejectsTraversal(t *testing.T) {
+	for _, entries := range [][]*tar.Header{
+		{{Name: "../evil", Typeflag: tar.TypeReg, Mode: 0644}},
+		{{Name: "/etc/evil", Typeflag: tar.TypeReg, Mode: 0644}},
+		{
+			{Name: "out", Typeflag: tar.TypeSymlink, Linkname: "/tmp"},
+			{Name: "out/evil", Typeflag: tar.TypeReg, Mode: 0644},
+		},
+	} {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, h := range entries {
+			tw.WriteHeader(h)
+		}
+		tw.Close()
+
+		if runtime.GOOS == "windows" && entries[0].Typeflag == tar.TypeSymlink {
+			continue
+		}
+		if err := readTar(&buf, t.TempDir()); err == nil {
+			t.Errorf("Expected error for %s", entries[len(entries)-1].Name)
+		}
+	}
+}
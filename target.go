@@ -0,0 +1,120 @@
+package easyssh
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// String returns the connection string "user@host:port" of MakeConfig, which
+// never contains any secrets. User and port are left out if not set. It can be
+// parsed by ParseTarget.
+func (ssh_conf *MakeConfig) String() string {
+	s := ssh_conf.Server
+	if ssh_conf.Port != "" {
+		s = net.JoinHostPort(ssh_conf.Server, ssh_conf.Port)
+	} else if strings.Contains(s, ":") {
+		s = "[" + s + "]"
+	}
+
+	if ssh_conf.User != "" {
+		s = ssh_conf.User + "@" + s
+	}
+	return s
+}
+
+// Redacted returns a description of MakeConfig for logs, listing the
+// connection string and the configured credentials with their secrets
+// replaced by "[REDACTED]".
+func (ssh_conf *MakeConfig) Redacted() string {
+	var details []string
+
+	if ssh_conf.Password != "" {
+		details = append(details, "password="+redacted)
+	}
+	if ssh_conf.Key != "" {
+		details = append(details, "key="+ssh_conf.Key)
+	}
+	if len(ssh_conf.KeyData) > 0 {
+		details = append(details, "key-data="+redacted)
+	}
+	if ssh_conf.KeyPassphrase != "" {
+		details = append(details, "passphrase="+redacted)
+	}
+	if ssh_conf.JumpHost != nil {
+		details = append(details, "jump="+ssh_conf.JumpHost.String())
+	}
+	if ssh_conf.Proxy != "" {
+		if u, err := url.Parse(ssh_conf.Proxy); err == nil {
+			details = append(details, "proxy="+u.Redacted())
+		} else {
+			details = append(details, "proxy="+redacted)
+		}
+	}
+
+	if len(details) == 0 {
+		return ssh_conf.String()
+	}
+	return ssh_conf.String() + " (" + strings.Join(details, ", ") + ")"
+}
+
+// ParseTarget parses a connection string of the form "[user@]host[:port]", as
+// returned by String, or an URL of the form "ssh://[user@]host[:port]" into a
+// MakeConfig. IPv6 addresses with a port need to be put in brackets (ex.
+// "root@[2001:db8::1]:2222"). Nothing else (ex. the user's ssh_config) is taken
+// into account, use NewConnection for that.
+func ParseTarget(target string) (*MakeConfig, error) {
+	if strings.HasPrefix(target, "ssh://") {
+		return parseTargetURL(target)
+	}
+
+	cfg := &MakeConfig{}
+
+	if pos := strings.LastIndex(target, "@"); pos != -1 {
+		cfg.User = target[:pos]
+		target = target[pos+1:]
+	}
+
+	switch {
+	case strings.HasPrefix(target, "[") && strings.HasSuffix(target, "]"):
+		cfg.Server = target[1 : len(target)-1]
+	case strings.Count(target, ":") > 1 && !strings.HasPrefix(target, "["):
+		cfg.Server = target
+	case strings.Contains(target, ":"):
+		host, port, err := net.SplitHostPort(target)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid target '%s': %s", target, err)
+		}
+		cfg.Server, cfg.Port = host, port
+	default:
+		cfg.Server = target
+	}
+
+	if cfg.Server == "" {
+		return nil, fmt.Errorf("Invalid target '%s': no host", target)
+	}
+	return cfg, nil
+}
+
+// parses an ssh:// URL
+func parseTargetURL(target string) (*MakeConfig, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid target '%s': %s", target, err)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("Invalid target '%s': no host", target)
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return nil, fmt.Errorf("Invalid target '%s': unexpected path", target)
+	}
+
+	cfg := &MakeConfig{Server: u.Hostname(), Port: u.Port()}
+	if u.User != nil {
+		cfg.User = u.User.Username()
+	}
+	return cfg, nil
+}
@@ -0,0 +1,34 @@
+package easyssh
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+)
+
+// UploadTemplate renders tmpl with data and writes the result to remotePath
+// with the permissions mode. The content is written to a temporary file next
+// to remotePath first, which replaces it once complete, so readers never see
+// a partially written file. Nothing is uploaded if rendering fails.
+func (ssh_conf *MakeConfig) UploadTemplate(tmpl *template.Template, data interface{}, remotePath string, mode os.FileMode) error {
+	var content bytes.Buffer
+	if err := tmpl.Execute(&content, data); err != nil {
+		return fmt.Errorf("Error rendering template: %s", err)
+	}
+
+	ssh_conf.logf(LogDebug1, "Uploading template %s to %s:%s (%d bytes)", tmpl.Name(), ssh_conf.address(), remotePath, content.Len())
+
+	tmp := ssh_conf.tempName(remotePath)
+	q := shellQuote(tmp)
+	// noclobber makes the shell create the file exclusively, so a file or
+	// symlink planted at the temporary name is never written through
+	command := fmt.Sprintf("umask 077 && set -C && cat > %s && chmod %04o %s", q, mode.Perm(), q)
+	_, stderr, err := ssh_conf.captureUntil(command, &content, nil)
+	if err != nil {
+		ssh_conf.capture("rm -f -- " + q)
+		return remoteFileError("write", remotePath, stderr, err)
+	}
+
+	return ssh_conf.replaceFile(tmp, remotePath, "")
+}
@@ -0,0 +1,60 @@
+package easyssh
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestUploadTemplate(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	cfg.Rand = func() float64 { return 0.5 }
+
+	var written string
+	srv.HandleDefault(func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		if strings.HasPrefix(command, "umask 077 && set -C && cat > ") {
+			data, _ := ioutil.ReadAll(stdin)
+			written = string(data)
+		}
+		return 0
+	})
+
+	tmpl := template.Must(template.New("nginx").Parse("listen {{.Port}};\n"))
+	if err := cfg.UploadTemplate(tmpl, map[string]int{"Port": 8080}, "/etc/nginx/site.conf", 0640); err != nil {
+		t.Fatalf("Error uploading template: %s", err)
+	}
+
+	if written != "listen 8080;\n" {
+		t.Errorf("Expected the rendered template to be written, got %q", written)
+	}
+	commands := srv.Commands()
+	if len(commands) != 2 {
+		t.Fatalf("Expected 2 commands, got %v", commands)
+	}
+	tmp := "'/etc/nginx/.site.conf.800000000000.tmp'"
+	expected := []string{
+		"umask 077 && set -C && cat > " + tmp + " && chmod 0640 " + tmp,
+		"mv -f -- " + tmp + " '/etc/nginx/site.conf'",
+	}
+	for i := range expected {
+		if commands[i] != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], commands[i])
+		}
+	}
+}
+
+func TestUploadTemplateRenderError(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+
+	tmpl := template.Must(template.New("broken").Option("missingkey=error").Parse("{{.Missing}}"))
+	if err := cfg.UploadTemplate(tmpl, map[string]int{}, "/etc/broken.conf", 0644); err == nil {
+		t.Errorf("Expected an error rendering the template")
+	}
+	if commands := srv.Commands(); len(commands) != 0 {
+		t.Errorf("Expected nothing to be uploaded, got %v", commands)
+	}
+}
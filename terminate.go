@@ -0,0 +1,145 @@
+package easyssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrCommandTimeout is matched by the *TimeoutError returned by RunTimeout.
+var ErrCommandTimeout = errors.New("Command timed out")
+
+// TimeoutError is returned if a command did not finish within the timeout
+// given to RunTimeout. It matches ErrCommandTimeout as well as
+// context.DeadlineExceeded.
+type TimeoutError struct {
+	Command string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("Command '%s' timed out after %s", e.Command, e.Timeout)
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrCommandTimeout || target == context.DeadlineExceeded
+}
+
+// TerminateGrace is the time RunContext gives a command to exit after SIGTERM
+// before the session is closed.
+var TerminateGrace = 5 * time.Second
+
+// RemoteCommand is a command running on the remote machine, started by
+// StartCommand.
+type RemoteCommand struct {
+	session *ssh.Session
+	clock   Clock
+	done    chan struct{}
+	status  int
+	err     error
+}
+
+// StartCommand starts command on the remote machine without waiting for it to
+// exit. Its stdout and stderr are copied to the given writers, which may be nil
+// to discard the output. The command needs to be waited for or terminated.
+func (ssh_conf *MakeConfig) StartCommand(command string, stdout, stderr io.Writer) (*RemoteCommand, error) {
+	session, err := ssh_conf.connect()
+	if err != nil {
+		return nil, err
+	}
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	if err := ssh_conf.start(session.Session, command); err != nil {
+		session.Close()
+		return nil, err
+	}
+	stopSignals := ssh_conf.forwardSignals(session.Session)
+
+	c := &RemoteCommand{session: session.Session, clock: ssh_conf.clock(), done: make(chan struct{})}
+	ssh_conf.spawn(func() {
+		c.status, c.err = exitStatusOf(ssh_conf.wait(session.Session))
+		stopSignals()
+		session.Close()
+		close(c.done)
+	})
+
+	return c, nil
+}
+
+// Signal sends sig (ex. ssh.SIGINT) to the command. Servers are free to ignore
+// this, OpenSSH supports it since version 8.1.
+func (c *RemoteCommand) Signal(sig ssh.Signal) error {
+	return c.session.Signal(sig)
+}
+
+// Wait waits for the command to exit and returns its exit status, like RunTo.
+// The error is only set if the command did not exit normally (ex. because it
+// was terminated or the connection dropped).
+func (c *RemoteCommand) Wait() (exitStatus int, err error) {
+	<-c.done
+	return c.status, c.err
+}
+
+// Terminate sends SIGTERM to the command and waits up to grace for it to exit
+// before closing the session, which drops the command if the server ignored
+// the signal. It returns like Wait.
+func (c *RemoteCommand) Terminate(grace time.Duration) (exitStatus int, err error) {
+	c.Signal(ssh.SIGTERM)
+
+	select {
+	case <-c.done:
+	case <-c.clock.After(grace):
+		c.session.Close()
+	}
+	return c.Wait()
+}
+
+// RunContext works like RunTo, but terminates the command (see Terminate) with
+// TerminateGrace once ctx is done and returns the error of ctx then.
+func (ssh_conf *MakeConfig) RunContext(ctx context.Context, command string, stdout, stderr io.Writer) (exitStatus int, err error) {
+	c, err := ssh_conf.StartCommand(command, stdout, stderr)
+	if err != nil {
+		return -1, err
+	}
+
+	select {
+	case <-c.done:
+		return c.Wait()
+	case <-ctx.Done():
+		ssh_conf.logf(LogDebug1, "Terminating '%s' on %s: %s", command, ssh_conf.address(), ctx.Err())
+		exitStatus, _ = c.Terminate(TerminateGrace)
+		return exitStatus, ctx.Err()
+	}
+}
+
+// RunTimeout runs command like Run, but terminates it (see Terminate) if it
+// does not finish within timeout and returns a *TimeoutError together with the
+// output so far then.
+func (ssh_conf *MakeConfig) RunTimeout(command string, timeout time.Duration) (string, error) {
+	var output bytes.Buffer
+	w := &lockedWriter{w: &output}
+
+	c, err := ssh_conf.StartCommand(command, w, w)
+	if err != nil {
+		return "", err
+	}
+
+	select {
+	case <-c.done:
+		_, err = c.Wait()
+	case <-ssh_conf.clock().After(timeout):
+		ssh_conf.logf(LogError, "Command '%s' on %s timed out after %s", command, ssh_conf.address(), timeout)
+		c.Terminate(TerminateGrace)
+		err = &TimeoutError{Command: command, Timeout: timeout}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return output.String(), err
+}
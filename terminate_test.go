@@ -0,0 +1,138 @@
+package easyssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
+)
+
+func TestTimeoutError(t *testing.T) {
+	var err error = &TimeoutError{Command: "sleep 100", Timeout: time.Second}
+
+	if !errors.Is(err, ErrCommandTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error to match ErrCommandTimeout and context.DeadlineExceeded")
+	}
+	if err.Error() != "Command 'sleep 100' timed out after 1s" {
+		t.Errorf("Unexpected error message: %s", err)
+	}
+}
+
+// handleUntilSignal makes srv run commands until they receive SIGTERM, which
+// makes them exit with status 143 like a shell would.
+func handleUntilSignal(srv *testserver.Server) {
+	srv.HandleDefault(func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.WriteString(stdout, "started\n")
+		for {
+			for _, sig := range srv.Signals() {
+				if sig == string(ssh.SIGTERM) {
+					return 143
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+}
+
+func TestTerminate(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	handleUntilSignal(srv)
+
+	var output bytes.Buffer
+	c, err := cfg.StartCommand("sleep 100", &output, nil)
+	if err != nil {
+		t.Fatalf("Error starting command: %s", err)
+	}
+	status, err := c.Terminate(5 * time.Second)
+	if err != nil || status != 143 {
+		t.Errorf("Expected command to exit with status 143 on SIGTERM, got %d (%v)", status, err)
+	}
+	if signals := srv.Signals(); len(signals) != 1 || signals[0] != "TERM" {
+		t.Errorf("Expected SIGTERM to be delivered, got %v", signals)
+	}
+}
+
+func TestTerminateGraceClosesSession(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	closed := make(chan struct{})
+	srv.HandleDefault(func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		// ignores signals and runs until the session is closed
+		for {
+			if _, err := io.WriteString(stdout, "."); err != nil {
+				close(closed)
+				return 0
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cfg.Clock = clock
+
+	c, err := cfg.StartCommand("sleep 100", nil, nil)
+	if err != nil {
+		t.Fatalf("Error starting command: %s", err)
+	}
+	if status, err := c.Terminate(time.Minute); err == nil || status != -1 {
+		t.Errorf("Expected command to be dropped without exit status, got %d (%v)", status, err)
+	}
+	if len(clock.waited) != 1 || clock.waited[0] != time.Minute {
+		t.Errorf("Expected to wait for the grace period, got %v", clock.waited)
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected session to be closed after the grace period")
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	handleUntilSignal(srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	output := &notifyWriter{written: make(chan struct{})}
+	go func() {
+		<-output.written
+		cancel()
+	}()
+
+	status, err := cfg.RunContext(ctx, "sleep 100", output, nil)
+	if err != context.Canceled || status != 143 {
+		t.Errorf("Expected context.Canceled and exit status 143, got %d (%v)", status, err)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	handleUntilSignal(srv)
+
+	output, err := cfg.RunTimeout("sleep 100", 50*time.Millisecond)
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Timeout != 50*time.Millisecond {
+		t.Errorf("Expected *TimeoutError, got %v", err)
+	}
+	if output != "started\n" {
+		t.Errorf("Expected output so far, got %q", output)
+	}
+}
+
+// notifyWriter closes written on the first write.
+type notifyWriter struct {
+	once    sync.Once
+	written chan struct{}
+}
+
+func (w *notifyWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.written) })
+	return len(p), nil
+}
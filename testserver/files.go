@@ -0,0 +1,185 @@
+package testserver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+// open flags of SFTP requests
+const (
+	flagRead  = 0x01
+	flagWrite = 0x02
+	flagCreat = 0x08
+	flagTrunc = 0x10
+)
+
+// WriteFile stores content as the file at the absolute path, which can be
+// downloaded from the server. The parent directory needs to exist.
+func (s *Server) WriteFile(name string, content []byte) error {
+	req := sftp.NewRequest("Put", name)
+	req.Flags = flagWrite | flagCreat | flagTrunc
+	w, err := s.files.FilePut.Filewrite(req)
+	if err != nil {
+		return &os.PathError{Op: "write", Path: name, Err: err}
+	}
+	_, err = w.WriteAt(content, 0)
+	return err
+}
+
+// ReadFile returns the content of the file at the absolute path (ex. after it
+// has been uploaded).
+func (s *Server) ReadFile(name string) ([]byte, error) {
+	info, err := s.stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, &os.PathError{Op: "read", Path: name, Err: fmt.Errorf("is a directory")}
+	}
+
+	req := sftp.NewRequest("Get", name)
+	req.Flags = flagRead
+	r, err := s.files.FileGet.Fileread(req)
+	if err != nil {
+		return nil, &os.PathError{Op: "read", Path: name, Err: err}
+	}
+
+	content := make([]byte, info.Size())
+	if _, err := r.ReadAt(content, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return content, nil
+}
+
+// Mkdir creates the directory at the absolute path.
+func (s *Server) Mkdir(name string) error {
+	if err := s.files.FileCmd.Filecmd(sftp.NewRequest("Mkdir", name)); err != nil {
+		return &os.PathError{Op: "mkdir", Path: name, Err: err}
+	}
+	return nil
+}
+
+// fileCmder wraps the file commands of sftp.InMemHandler, which only support
+// changing the size of files, to accept changes of the permissions, owner and
+// times of any existing file or directory. Those are not kept.
+type fileCmder struct {
+	sftp.FileCmder
+	s *Server
+}
+
+func (c fileCmder) Filecmd(r *sftp.Request) error {
+	if r.Method == "Setstat" && !r.AttrFlags().Size {
+		_, err := c.s.stat(r.Filepath)
+		return err
+	}
+	return c.FileCmder.Filecmd(r)
+}
+
+func (c fileCmder) PosixRename(r *sftp.Request) error {
+	return c.FileCmder.(sftp.PosixRenameFileCmder).PosixRename(r)
+}
+
+func (s *Server) stat(name string) (os.FileInfo, error) {
+	lister, err := s.files.FileList.Filelist(sftp.NewRequest("Stat", name))
+	if err != nil {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: err}
+	}
+
+	infos := make([]os.FileInfo, 1)
+	if n, err := lister.ListAt(infos, 0); n == 0 {
+		if err == nil || err == io.EOF {
+			err = os.ErrNotExist
+		}
+		return nil, &os.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return infos[0], nil
+}
+
+// scp is the Handler for "scp -t" and "scp -f", receiving files from and
+// sending files to the client.
+func (s *Server) scp(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+	args := strings.Fields(command)[1:]
+	var sink, source bool
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		sink = sink || args[0] == "-t"
+		source = source || args[0] == "-f"
+		args = args[1:]
+	}
+	target := unquote(strings.Join(args, " "))
+
+	fail := func(err error) int {
+		fmt.Fprintf(stdout, "\x01scp: %s\n", err)
+		return 1
+	}
+
+	r := bufio.NewReader(stdin)
+	switch {
+	case sink:
+		if info, err := s.stat(target); err == nil && info.IsDir() {
+			return fail(fmt.Errorf("%s: Is a directory", target))
+		}
+		stdout.Write([]byte{0})
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return 0
+			}
+			switch line[0] {
+			case 'T':
+				stdout.Write([]byte{0})
+			case 'C':
+				var mode string
+				var size int64
+				if _, err := fmt.Sscanf(line[1:], "%s %d", &mode, &size); err != nil {
+					return fail(fmt.Errorf("Protocol error: %q", line))
+				}
+				stdout.Write([]byte{0})
+
+				content := make([]byte, size+1)
+				if _, err := io.ReadFull(r, content); err != nil {
+					return 1
+				}
+				if err := s.WriteFile(target, content[:size]); err != nil {
+					return fail(err)
+				}
+				stdout.Write([]byte{0})
+			default:
+				return fail(fmt.Errorf("Protocol error: %q", line))
+			}
+		}
+
+	case source:
+		if _, err := r.ReadByte(); err != nil {
+			return 1
+		}
+		content, err := s.ReadFile(target)
+		if err != nil {
+			return fail(fmt.Errorf("%s: No such file or directory", target))
+		}
+
+		fmt.Fprintf(stdout, "C0644 %d %s\n", len(content), path.Base(target))
+		if _, err := r.ReadByte(); err != nil {
+			return 1
+		}
+		stdout.Write(append(content, 0))
+		r.ReadByte()
+		return 0
+	}
+
+	return fail(fmt.Errorf("Unsupported command: %s", command))
+}
+
+// unquote removes the single quotes a shell would remove from word.
+func unquote(word string) string {
+	if !strings.HasPrefix(word, "'") {
+		return word
+	}
+	return strings.Replace(strings.Trim(word, "'"), `'\''`, "'", -1)
+}
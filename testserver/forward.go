@@ -0,0 +1,187 @@
+package testserver
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// payload of tcpip-forward requests
+type forwardRequest struct {
+	Addr string
+	Port uint32
+}
+
+// payload of direct-tcpip and forwarded-tcpip channels
+type tcpipChannel struct {
+	Addr       string
+	Port       uint32
+	OriginAddr string
+	OriginPort uint32
+}
+
+// forwards holds the ports the client asked the server to listen on. They are
+// always opened on the loopback interface.
+type forwards struct {
+	conn      *ssh.ServerConn
+	mu        sync.Mutex
+	listeners map[string]net.Listener
+}
+
+func (f *forwards) handleRequests(reqs <-chan *ssh.Request) {
+	for req := range reqs {
+		var payload forwardRequest
+		switch req.Type {
+		case "tcpip-forward":
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(payload.Port))))
+			if err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			payload.Port = uint32(l.Addr().(*net.TCPAddr).Port)
+
+			f.mu.Lock()
+			f.listeners[net.JoinHostPort(payload.Addr, strconv.Itoa(int(payload.Port)))] = l
+			f.mu.Unlock()
+
+			go f.accept(l, payload)
+			req.Reply(true, ssh.Marshal(struct{ Port uint32 }{payload.Port}))
+
+		case "cancel-tcpip-forward":
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			key := net.JoinHostPort(payload.Addr, strconv.Itoa(int(payload.Port)))
+
+			f.mu.Lock()
+			l, ok := f.listeners[key]
+			delete(f.listeners, key)
+			f.mu.Unlock()
+
+			if ok {
+				l.Close()
+			}
+			req.Reply(ok, nil)
+
+		default:
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+		}
+	}
+}
+
+// accept passes the connections to l on to the client.
+func (f *forwards) accept(l net.Listener, forward forwardRequest) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		origin := conn.RemoteAddr().(*net.TCPAddr)
+		channel, reqs, err := f.conn.OpenChannel("forwarded-tcpip", ssh.Marshal(tcpipChannel{
+			Addr:       forward.Addr,
+			Port:       forward.Port,
+			OriginAddr: origin.IP.String(),
+			OriginPort: uint32(origin.Port),
+		}))
+		if err != nil {
+			conn.Close()
+			continue
+		}
+		go ssh.DiscardRequests(reqs)
+		go proxy(channel, conn)
+	}
+}
+
+// close stops listening on all forwarded ports.
+func (f *forwards) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for key, l := range f.listeners {
+		l.Close()
+		delete(f.listeners, key)
+	}
+}
+
+// directTCPIP connects the channel to the address the client asked for.
+func directTCPIP(newChannel ssh.NewChannel) {
+	var payload tcpipChannel
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, "Invalid payload")
+		return
+	}
+
+	conn, err := net.Dial("tcp", net.JoinHostPort(payload.Addr, strconv.Itoa(int(payload.Port))))
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	channel, reqs, err := newChannel.Accept()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	proxy(channel, conn)
+}
+
+// directStreamlocal connects the channel to the Unix socket the client asked
+// for.
+func directStreamlocal(newChannel ssh.NewChannel) {
+	var payload struct {
+		SocketPath string
+		Reserved0  string
+		Reserved1  uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, "Invalid payload")
+		return
+	}
+
+	conn, err := net.Dial("unix", payload.SocketPath)
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	channel, reqs, err := newChannel.Accept()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	proxy(channel, conn)
+}
+
+// proxy copies data between channel and conn until both directions are done.
+func proxy(channel ssh.Channel, conn net.Conn) {
+	defer conn.Close()
+	defer channel.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(channel, conn)
+		channel.CloseWrite()
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(conn, channel)
+		if c, ok := conn.(interface{ CloseWrite() error }); ok {
+			c.CloseWrite()
+		}
+		done <- struct{}{}
+	}()
+	<-done
+	<-done
+}
@@ -0,0 +1,513 @@
+// Package testserver provides an in-memory SSH server for hermetic tests of
+// code using easyssh. Commands are answered by handlers registered for them,
+// files uploaded or downloaded using scp or SFTP are kept in memory and TCP
+// connections are forwarded on the loopback interface, as well as connections
+// to local Unix sockets:
+//
+//	srv, err := testserver.New("deploy", "secret")
+//	defer srv.Close()
+//	srv.Handle("uptime", testserver.Reply("up 3 days\n", 0))
+//	srv.WriteFile("/etc/hostname", []byte("web1\n"))
+//
+//	host := &easyssh.MakeConfig{User: "deploy", Password: "secret",
+//		Server: srv.Host(), Port: srv.Port(),
+//		HostKeyCallback: ssh.FixedHostKey(srv.HostKey())}
+package testserver
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// Handler runs command on the Server. It reads the input of the command from
+// stdin, writes its output to stdout and stderr and returns its exit status.
+type Handler func(command string, stdin io.Reader, stdout, stderr io.Writer) int
+
+// Reply returns a Handler writing output to stdout and exiting with status.
+func Reply(output string, status int) Handler {
+	return func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		io.WriteString(stdout, output)
+		return status
+	}
+}
+
+// Server is an SSH server listening on a random port of the loopback
+// interface. It accepts the user it was created for, authenticating using the
+// password or a key passed to Authorize.
+type Server struct {
+	user     string
+	password string
+	hostKey  ssh.Signer
+	listener net.Listener
+	files    sftp.Handlers
+
+	mu        sync.Mutex
+	keys      []ssh.PublicKey
+	banner    string
+	questions []string
+	answers   []string
+	handlers  map[string]Handler
+	fallback  Handler
+	systems   map[string]Handler
+	shell     Handler
+	commands  []string
+	signals   []string
+	requests  []string
+	env       []string
+	ignored   map[string]bool
+	conns     map[net.Conn]struct{}
+	accepted  int
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// New starts a Server for user. If password is empty, only key authentication
+// is possible.
+func New(user, password string) (*Server, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	hostKey, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		user:     user,
+		password: password,
+		hostKey:  hostKey,
+		listener: listener,
+		files:    sftp.InMemHandler(),
+		handlers: map[string]Handler{},
+		systems:  map[string]Handler{},
+		ignored:  map[string]bool{},
+		conns:    map[net.Conn]struct{}{},
+	}
+
+	s.files.FileCmd = fileCmder{FileCmder: s.files.FileCmd, s: s}
+
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address the server listens on (ex. "127.0.0.1:40231").
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Host returns the IP address the server listens on.
+func (s *Server) Host() string {
+	host, _, _ := net.SplitHostPort(s.Addr())
+	return host
+}
+
+// Port returns the port the server listens on.
+func (s *Server) Port() string {
+	_, port, _ := net.SplitHostPort(s.Addr())
+	return port
+}
+
+// HostKey returns the public key the server identifies with.
+func (s *Server) HostKey() ssh.PublicKey {
+	return s.hostKey.PublicKey()
+}
+
+// Authorize allows the user to log in using key.
+func (s *Server) Authorize(key ssh.PublicKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append(s.keys, key)
+}
+
+// SetBanner makes the server send banner to clients before authentication.
+func (s *Server) SetBanner(banner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.banner = banner
+}
+
+// SetChallenge enables keyboard-interactive authentication, asking questions
+// without echo and accepting the user if the replies equal answers.
+func (s *Server) SetChallenge(questions, answers []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.questions = questions
+	s.answers = answers
+}
+
+// Handle registers h for command, which needs to match exactly.
+func (s *Server) Handle(command string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[command] = h
+}
+
+// HandleDefault registers h for all commands without a handler of their own.
+// Without a default handler, those fail like a missing command (exit status
+// 127).
+func (s *Server) HandleDefault(h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fallback = h
+}
+
+// HandleSubsystem registers h for the subsystem name (ex. "netconf"). It is
+// called with the name as command. The "sftp" subsystem is built in.
+func (s *Server) HandleSubsystem(name string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.systems[name] = h
+}
+
+// HandleShell registers h for interactive shells, which are started with an
+// empty command. Without a shell handler, shell requests are rejected.
+func (s *Server) HandleShell(h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shell = h
+}
+
+// IgnoreRequest makes the server never answer session requests of reqType
+// (ex. "exec" or "pty-req"), like a server which hangs.
+func (s *Server) IgnoreRequest(reqType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ignored[reqType] = true
+}
+
+// Commands returns the commands run on the server so far in order.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// Requests returns the types of the session requests (ex. "pty-req" or
+// "exec") received so far in order, up to the start of the commands.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Env returns the environment variables set by env requests so far in order,
+// as "NAME=value".
+func (s *Server) Env() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.env...)
+}
+
+// Signals returns the names of the signals (ex. "TERM") sent to running
+// commands so far in order. Handlers can poll it to react to signals.
+func (s *Server) Signals() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.signals...)
+}
+
+// Connections returns the number of client connections currently open.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns)
+}
+
+// Accepted returns the number of client connections accepted so far.
+func (s *Server) Accepted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accepted
+}
+
+// Close stops the server and closes all connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	config := &ssh.ServerConfig{
+		BannerCallback: func(meta ssh.ConnMetadata) string {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.banner
+		},
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if meta.User() == s.user && s.password != "" && string(password) == s.password {
+				return nil, nil
+			}
+			return nil, errors.New("Access denied")
+		},
+		KeyboardInteractiveCallback: func(meta ssh.ConnMetadata, challenge ssh.KeyboardInteractiveChallenge) (*ssh.Permissions, error) {
+			s.mu.Lock()
+			questions, answers := s.questions, s.answers
+			s.mu.Unlock()
+			if meta.User() != s.user || questions == nil {
+				return nil, errors.New("Access denied")
+			}
+
+			replies, err := challenge(meta.User(), "", questions, make([]bool, len(questions)))
+			if err != nil {
+				return nil, err
+			}
+			if len(replies) != len(answers) {
+				return nil, errors.New("Access denied")
+			}
+			for i := range answers {
+				if replies[i] != answers[i] {
+					return nil, errors.New("Access denied")
+				}
+			}
+			return nil, nil
+		},
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			for _, k := range s.keys {
+				if meta.User() == s.user && bytes.Equal(k.Marshal(), key.Marshal()) {
+					return nil, nil
+				}
+			}
+			return nil, errors.New("Access denied")
+		},
+	}
+	config.AddHostKey(s.hostKey)
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.accepted++
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			s.handleConn(conn, config)
+
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+
+	serverConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+
+	forwards := &forwards{conn: serverConn, listeners: map[string]net.Listener{}}
+	defer forwards.close()
+	go forwards.handleRequests(reqs)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "session":
+		case "direct-tcpip":
+			go directTCPIP(newChannel)
+			continue
+		case "direct-streamlocal@openssh.com":
+			go directStreamlocal(newChannel)
+			continue
+		default:
+			newChannel.Reject(ssh.UnknownChannelType, "Unsupported channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handleSession(channel, requests)
+		}()
+	}
+}
+
+// handleSession answers the requests of a session until a command is run or
+// a subsystem is started.
+func (s *Server) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for req := range requests {
+		s.mu.Lock()
+		s.requests = append(s.requests, req.Type)
+		ignored := s.ignored[req.Type]
+		s.mu.Unlock()
+		if ignored {
+			continue
+		}
+
+		switch req.Type {
+		case "exec":
+			var payload struct{ Command string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+
+			go s.recordSignals(requests)
+			status := s.exec(payload.Command, channel)
+			channel.CloseWrite()
+			channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+			return
+
+		case "subsystem":
+			var payload struct{ Name string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+
+			s.mu.Lock()
+			h, ok := s.systems[payload.Name]
+			s.mu.Unlock()
+
+			if !ok && payload.Name != "sftp" {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			go ssh.DiscardRequests(requests)
+
+			if ok {
+				status := h(payload.Name, channel, channel, channel.Stderr())
+				channel.CloseWrite()
+				channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+				return
+			}
+			server := sftp.NewRequestServer(channel, s.files)
+			server.Serve()
+			server.Close()
+			return
+
+		case "shell":
+			s.mu.Lock()
+			h := s.shell
+			s.mu.Unlock()
+
+			if h == nil {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			go s.recordSignals(requests)
+
+			status := h("", channel, channel, channel.Stderr())
+			channel.CloseWrite()
+			channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+			return
+
+		case "env":
+			var payload struct{ Name, Value string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err == nil {
+				s.mu.Lock()
+				s.env = append(s.env, payload.Name+"="+payload.Value)
+				s.mu.Unlock()
+			}
+			if req.WantReply {
+				req.Reply(true, nil)
+			}
+
+		case "pty-req", "window-change", "signal":
+			if req.WantReply {
+				req.Reply(true, nil)
+			}
+
+		default:
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+		}
+	}
+}
+
+// recordSignals answers the requests sent while a command is running and
+// records the signals among them.
+func (s *Server) recordSignals(requests <-chan *ssh.Request) {
+	for req := range requests {
+		if req.Type == "signal" {
+			var payload struct{ Signal string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err == nil {
+				s.mu.Lock()
+				s.signals = append(s.signals, payload.Signal)
+				s.mu.Unlock()
+			}
+		}
+		if req.WantReply {
+			req.Reply(false, nil)
+		}
+	}
+}
+
+// exec runs command using its handler and returns the exit status.
+func (s *Server) exec(command string, channel ssh.Channel) int {
+	s.mu.Lock()
+	s.commands = append(s.commands, command)
+	h, ok := s.handlers[command]
+	if !ok {
+		h = s.fallback
+	}
+	s.mu.Unlock()
+
+	if !ok && strings.HasPrefix(command, "scp ") {
+		h = s.scp
+	}
+	if h == nil {
+		name := command
+		if i := strings.IndexByte(command, ' '); i >= 0 {
+			name = command[:i]
+		}
+		fmt.Fprintf(channel.Stderr(), "sh: 1: %s: not found\n", name)
+		return 127
+	}
+
+	return h(command, channel, channel, channel.Stderr())
+}
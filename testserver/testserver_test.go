@@ -0,0 +1,127 @@
+package testserver
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+func dial(t *testing.T, srv *Server, auth ssh.AuthMethod) (*ssh.Client, error) {
+	return ssh.Dial("tcp", srv.Addr(), &ssh.ClientConfig{
+		User:            "test",
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: ssh.FixedHostKey(srv.HostKey()),
+	})
+}
+
+func TestServerRunsHandlers(t *testing.T) {
+	srv, err := New("test", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	srv.Handle("uptime", Reply("up 3 days\n", 0))
+	srv.Handle("tr a-z A-Z", func(command string, stdin io.Reader, stdout, stderr io.Writer) int {
+		input, _ := ioutil.ReadAll(stdin)
+		stdout.Write(bytes.ToUpper(input))
+		return 3
+	})
+
+	if _, err := dial(t, srv, ssh.Password("wrong")); err == nil {
+		t.Errorf("Expected wrong password to be rejected")
+	}
+	client, err := dial(t, srv, ssh.Password("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	run := func(command, input string) (string, error) {
+		session, err := client.NewSession()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer session.Close()
+		session.Stdin = strings.NewReader(input)
+		out, err := session.CombinedOutput(command)
+		return string(out), err
+	}
+
+	if out, err := run("uptime", ""); err != nil || out != "up 3 days\n" {
+		t.Errorf("Expected canned output, got %q (%v)", out, err)
+	}
+	out, err := run("tr a-z A-Z", "hello")
+	if exitErr, ok := err.(*ssh.ExitError); !ok || exitErr.ExitStatus() != 3 || out != "HELLO" {
+		t.Errorf("Expected HELLO with exit status 3, got %q (%v)", out, err)
+	}
+	if _, err := run("missing", ""); err == nil || err.(*ssh.ExitError).ExitStatus() != 127 {
+		t.Errorf("Expected exit status 127 for unknown command, got %v", err)
+	}
+
+	expected := []string{"uptime", "tr a-z A-Z", "missing"}
+	if commands := srv.Commands(); strings.Join(commands, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected commands %q, got %q", expected, commands)
+	}
+}
+
+func TestServerSFTP(t *testing.T) {
+	srv, err := New("test", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	if err := srv.WriteFile("/motd", []byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := dial(t, srv, ssh.Password("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	sftpClient, err := sftp.NewClient(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sftpClient.Close()
+
+	f, err := sftpClient.Open("/motd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadAll(f)
+	f.Close()
+	if string(content) != "hello\n" {
+		t.Errorf("Expected file content, got %q", content)
+	}
+
+	f, err = sftpClient.Create("/upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("data"))
+	f.Close()
+	if content, err := srv.ReadFile("/upload"); err != nil || string(content) != "data" {
+		t.Errorf("Expected uploaded file, got %q (%v)", content, err)
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	for word, expected := range map[string]string{
+		"/tmp/file":      "/tmp/file",
+		"'/tmp/my file'": "/tmp/my file",
+		`'/tmp/it'\''s'`: "/tmp/it's",
+	} {
+		if result := unquote(word); result != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, word, result)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package easyssh
+
+import (
+	"io"
+	"time"
+)
+
+// throttle limits a transfer to rate bytes per second by waiting whenever it
+// is ahead of schedule.
+type throttle struct {
+	clock       Clock
+	rate        int64
+	start       time.Time
+	transferred int64
+}
+
+// returns a throttle limiting to rate bytes per second, or nil if rate is not
+// positive
+func (ssh_conf *MakeConfig) newThrottle(rate int64) *throttle {
+	if rate <= 0 {
+		return nil
+	}
+	return &throttle{clock: ssh_conf.clock(), rate: rate, start: ssh_conf.clock().Now()}
+}
+
+// chunk returns the number of bytes to transfer at once, so that no more than
+// a tenth of a second worth of data is sent in a single burst.
+func (t *throttle) chunk(n int) int {
+	max := t.rate / 10
+	if max < 1 {
+		max = 1
+	}
+	if int64(n) > max {
+		return int(max)
+	}
+	return n
+}
+
+// wait counts n transferred bytes and waits until they are due.
+func (t *throttle) wait(n int) {
+	t.transferred += int64(n)
+	due := t.start.Add(time.Duration(float64(t.transferred) / float64(t.rate) * float64(time.Second)))
+	if d := due.Sub(t.clock.Now()); d > 0 {
+		<-t.clock.After(d)
+	}
+}
+
+// reader returns r limited to the rate of the throttle, or r itself without
+// throttle.
+func (t *throttle) reader(r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	return &throttledReader{r, t}
+}
+
+// writer returns w limited to the rate of the throttle, or w itself without
+// throttle.
+func (t *throttle) writer(w io.Writer) io.Writer {
+	if t == nil {
+		return w
+	}
+	return &throttledWriter{w, t}
+}
+
+type throttledReader struct {
+	r io.Reader
+	t *throttle
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p[:r.t.chunk(len(p))])
+	r.t.wait(n)
+	return n, err
+}
+
+type throttledWriter struct {
+	w io.Writer
+	t *throttle
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		n, err := w.w.Write(p[written : written+w.t.chunk(len(p)-written)])
+		written += n
+		w.t.wait(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
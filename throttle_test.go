@@ -0,0 +1,59 @@
+package easyssh
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestThrottleReader(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cfg := &MakeConfig{Clock: clock}
+
+	r := cfg.newThrottle(1000).reader(strings.NewReader(strings.Repeat("x", 2500)))
+	n, err := io.Copy(ioutil.Discard, r)
+	if err != nil || n != 2500 {
+		t.Fatalf("Expected 2500 bytes, got %d (%v)", n, err)
+	}
+
+	if elapsed := clock.now.Sub(time.Unix(0, 0)); elapsed != 2500*time.Millisecond {
+		t.Errorf("Expected transfer to take 2.5s, got %s", elapsed)
+	}
+	for _, d := range clock.waited {
+		if d > 100*time.Millisecond {
+			t.Errorf("Expected bursts of at most 100ms, got %s", d)
+		}
+	}
+}
+
+func TestThrottleWriter(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cfg := &MakeConfig{Clock: clock}
+
+	var buf bytes.Buffer
+	w := cfg.newThrottle(100).writer(&buf)
+	if n, err := w.Write(bytes.Repeat([]byte("x"), 50)); err != nil || n != 50 {
+		t.Fatalf("Expected 50 bytes written, got %d (%v)", n, err)
+	}
+
+	if buf.Len() != 50 {
+		t.Errorf("Expected 50 bytes in buffer, got %d", buf.Len())
+	}
+	if len(clock.waited) != 5 {
+		t.Errorf("Expected 5 chunks, got %d", len(clock.waited))
+	}
+	if elapsed := clock.now.Sub(time.Unix(0, 0)); elapsed != 500*time.Millisecond {
+		t.Errorf("Expected write to take 500ms, got %s", elapsed)
+	}
+}
+
+func TestThrottleUnlimited(t *testing.T) {
+	var cfg MakeConfig
+	r := strings.NewReader("abc")
+	if cfg.newThrottle(0).reader(r) != io.Reader(r) {
+		t.Errorf("Expected reader to be unchanged without rate limit")
+	}
+}
@@ -0,0 +1,63 @@
+package easyssh
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrRequestTimeout is returned if the server did not answer a session request
+// (ex. pty-req, exec or subsystem) within RequestTimeout.
+var ErrRequestTimeout = errors.New("Timeout waiting for the server to answer a session request")
+
+// request calls f, which sends a request on session and waits for the reply. If
+// the server does not answer within RequestTimeout, the session is closed and
+// ErrRequestTimeout is returned.
+func (ssh_conf *MakeConfig) request(session *ssh.Session, f func() error) error {
+	if ssh_conf.RequestTimeout <= 0 {
+		return f()
+	}
+
+	result := make(chan error, 1)
+	ssh_conf.spawn(func() {
+		result <- f()
+	})
+
+	select {
+	case err := <-result:
+		return err
+	case <-ssh_conf.clock().After(ssh_conf.RequestTimeout):
+		session.Close()
+		return ErrRequestTimeout
+	}
+}
+
+// start starts command on session in the working directory Dir, honoring
+// InlineEnv, RequestTimeout and the Quota of commands.
+func (ssh_conf *MakeConfig) start(session *ssh.Session, command string) error {
+	if err := ssh_conf.checkQuota(true); err != nil {
+		return err
+	}
+
+	command, err := ssh_conf.withEnv(ssh_conf.inDir(command))
+	if err != nil {
+		return err
+	}
+	err = ssh_conf.request(session, func() error {
+		return session.Start(command)
+	})
+	if err == nil {
+		ssh_conf.commandStarted(session, command)
+	}
+	return err
+}
+
+// run runs command on session, honoring RequestTimeout for starting it.
+func (ssh_conf *MakeConfig) run(session *ssh.Session, command string) error {
+	if err := ssh_conf.start(session, command); err != nil {
+		return err
+	}
+	defer ssh_conf.forwardSignals(session)()
+
+	return ssh_conf.wait(session)
+}
@@ -0,0 +1,31 @@
+package easyssh
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	cfg.RequestTimeout = 100 * time.Millisecond
+
+	for _, reqType := range []string{"exec", "pty-req"} {
+		srv.IgnoreRequest(reqType)
+
+		start := time.Now()
+		var err error
+		if reqType == "exec" {
+			_, err = cfg.RunTo("uptime", nil, nil)
+		} else {
+			_, err = cfg.Run("uptime")
+		}
+		if !errors.Is(err, ErrRequestTimeout) {
+			t.Errorf("Expected ErrRequestTimeout for unanswered %s request, got %v", reqType, err)
+		}
+		if elapsed := time.Since(start); elapsed < cfg.RequestTimeout || elapsed > 2*time.Second {
+			t.Errorf("Expected %s request to time out after %s, took %s", reqType, cfg.RequestTimeout, elapsed)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package easyssh
+
+// Tracer creates spans for the connections, commands and transfers of a
+// MakeConfig, for distributed tracing (see the otelssh package for
+// OpenTelemetry). The methods may be called concurrently.
+type Tracer interface {
+	// Start starts a span for operation ("ssh.connect", "ssh.command",
+	// "ssh.upload" or "ssh.download") on host.
+	Start(host *MakeConfig, operation string) Span
+}
+
+// Span is a single operation traced by a Tracer.
+type Span interface {
+	// SetAttribute adds an attribute (ex. "ssh.command") to the span. The
+	// value is a string, int or int64.
+	SetAttribute(key string, value interface{})
+	// End ends the span, which failed if err is not nil.
+	End(err error)
+}
+
+// noSpan is used if no Tracer is set.
+type noSpan struct{}
+
+func (noSpan) SetAttribute(key string, value interface{}) {}
+func (noSpan) End(err error)                              {}
+
+// startSpan starts a span for operation using the Tracer of MakeConfig, with
+// the attributes common to all spans set.
+func (ssh_conf *MakeConfig) startSpan(operation string) Span {
+	if ssh_conf.Tracer == nil {
+		return noSpan{}
+	}
+
+	span := ssh_conf.Tracer.Start(ssh_conf, operation)
+	span.SetAttribute("server.address", ssh_conf.Server)
+	span.SetAttribute("server.port", ssh_conf.Port)
+	span.SetAttribute("ssh.user", ssh_conf.User)
+	return span
+}
@@ -0,0 +1,49 @@
+package easyssh
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+type testSpan struct {
+	operation string
+	attrs     map[string]interface{}
+	err       error
+	ended     bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *testSpan) End(err error)                              { s.err, s.ended = err, true }
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(host *MakeConfig, operation string) Span {
+	span := &testSpan{operation: operation, attrs: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return span
+}
+
+func TestTracerConnectSpan(t *testing.T) {
+	tracer := &testTracer{}
+	cfg := &MakeConfig{Server: "example.com", Port: "22", User: "test", Password: "secret", Tracer: tracer,
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, errors.New("network down")
+		}}
+
+	cfg.Connect()
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.operation != "ssh.connect" || !span.ended || span.err == nil {
+		t.Errorf("Expected failed ssh.connect span, got %+v", span)
+	}
+	if span.attrs["server.address"] != "example.com" || span.attrs["ssh.user"] != "test" {
+		t.Errorf("Expected host attributes, got %v", span.attrs)
+	}
+}
@@ -0,0 +1,224 @@
+package easyssh
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+// errUnavailable is returned by transfer methods if the server does not
+// support them, so the next method can be tried.
+var errUnavailable = errors.New("Transfer method not available")
+
+// Download copies sourceFile from the remote machine to targetFile on the local
+// machine. SFTP is used if the server supports it. Otherwise the file is
+// transferred using scp, or cat for minimal systems (ex. BusyBox or dropbear
+// based ones) which lack scp as well. An existing targetFile is only replaced
+// once the transfer succeeded.
+func (ssh_conf *MakeConfig) Download(sourceFile, targetFile string) error {
+	return ssh_conf.DownloadWithOptions(sourceFile, targetFile, DownloadOptions{})
+}
+
+// DownloadOptions changes how DownloadWithOptions transfers a file.
+type DownloadOptions struct {
+	// Progress is called with the progress of the download. The total size is
+	// not known in advance if the file is transferred using cat.
+	Progress ProgressFunc
+	// RateLimit limits the download to the given number of bytes per second
+	// (like scp -l). Zero means no limit.
+	RateLimit int64
+	// Verify compares the SHA-256 of the downloaded data to the one of the
+	// remote file afterwards and returns a *ChecksumError if they differ.
+	Verify bool
+}
+
+// DownloadWithOptions works like Download, but allows changing how the file is
+// transferred.
+func (ssh_conf *MakeConfig) DownloadWithOptions(sourceFile, targetFile string, opts DownloadOptions) error {
+	span := ssh_conf.startSpan("ssh.download")
+	span.SetAttribute("ssh.file", sourceFile)
+	err := ssh_conf.download(sourceFile, targetFile, opts, span)
+	span.End(err)
+	return err
+}
+
+// download implements DownloadWithOptions, setting the bytes received on span
+func (ssh_conf *MakeConfig) download(sourceFile, targetFile string, opts DownloadOptions, span Span) error {
+	client, err := ssh_conf.Connect()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	// the file is written next to targetFile and only renamed into place once
+	// complete, so a failed download keeps a previous version intact
+	dst, err := ioutil.TempFile(filepath.Dir(targetFile), "."+filepath.Base(targetFile)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(dst.Name())
+	defer dst.Close()
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(targetFile); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := dst.Chmod(mode); err != nil {
+		return err
+	}
+
+	counter := &byteCounter{w: dst}
+	var out io.Writer = counter
+	sum := sha256.New()
+	if opts.Verify {
+		out = io.MultiWriter(counter, sum)
+	}
+
+	progress := ssh_conf.newProgress(opts.Progress, -1)
+	writer := progress.writer(ssh_conf.newThrottle(opts.RateLimit).writer(out))
+
+	err = client.downloadSFTP(sourceFile, writer)
+	if err == errUnavailable {
+		ssh_conf.logf(LogDebug1, "SFTP not available on %s, falling back to scp", ssh_conf.address())
+		err = client.downloadSCP(sourceFile, writer)
+	}
+	if err == errUnavailable {
+		ssh_conf.logf(LogDebug1, "scp not available on %s, falling back to cat", ssh_conf.address())
+		err = client.downloadCat(sourceFile, writer)
+	}
+	if err != nil {
+		ssh_conf.logf(LogError, "Error downloading %s:%s to %s: %s", ssh_conf.address(), sourceFile, targetFile, err)
+		return err
+	}
+	progress.finish()
+	ssh_conf.bytesTransferred("download", counter.n)
+	span.SetAttribute("ssh.bytes", counter.n)
+
+	if opts.Verify {
+		if err := ssh_conf.verifyChecksum(sourceFile, sum.Sum(nil)); err != nil {
+			return err
+		}
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Rename(dst.Name(), targetFile)
+}
+
+// downloads sourceFile using SFTP
+func (c *Client) downloadSFTP(sourceFile string, dst io.Writer) error {
+	sftpClient, err := sftp.NewClient(c.client)
+	if err != nil {
+		return errUnavailable
+	}
+	defer sftpClient.Close()
+
+	src, err := sftpClient.Open(sourceFile)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if stat, err := src.Stat(); err == nil {
+		setTotal(dst, stat.Size())
+	}
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// downloads sourceFile using the source mode of scp
+func (c *Client) downloadSCP(sourceFile string, dst io.Writer) error {
+	session, err := c.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	w, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := c.config.start(session, "scp -f "+shellQuote(sourceFile)); err != nil {
+		return err
+	}
+
+	err = scpReceiveFile(w, bufio.NewReader(r), dst)
+	w.Close()
+	waitErr := c.config.wait(session)
+	if isCommandNotFound(waitErr) {
+		return errUnavailable
+	}
+	if err != nil {
+		return err
+	}
+
+	return waitErr
+}
+
+// downloads sourceFile using cat
+func (c *Client) downloadCat(sourceFile string, dst io.Writer) error {
+	session, err := c.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	var stderr strings.Builder
+	session.Stdout = dst
+	session.Stderr = &stderr
+
+	if err := c.config.run(session, "cat -- "+shellQuote(sourceFile)); err != nil {
+		return remoteFileError("download", sourceFile, []byte(stderr.String()), err)
+	}
+
+	return nil
+}
+
+// setTotal tells dst the size of the file written to it, if it tracks the
+// progress of a download.
+func setTotal(dst io.Writer, total int64) {
+	if w, ok := dst.(interface{ setTotal(int64) }); ok {
+		w.setTotal(total)
+	}
+}
+
+// isCommandNotFound checks whether err means the shell could not find the
+// command to run.
+func isCommandNotFound(err error) bool {
+	status, err := exitStatusOf(err)
+	return err == nil && status == 127
+}
+
+// uploads src using cat, for systems without scp
+func (ssh_conf *MakeConfig) uploadCat(src io.Reader, srcStat os.FileInfo, targetFile string, opts UploadOptions) error {
+	target := shellQuote(targetFile)
+	command := "cat > " + target
+	if opts.PreserveMode {
+		command += fmt.Sprintf(" && chmod %04o %s", srcStat.Mode().Perm(), target)
+	}
+	if opts.PreserveTimes {
+		command += fmt.Sprintf(" && touch -d @%d %s", srcStat.ModTime().Unix(), target)
+	}
+
+	_, stderr, err := ssh_conf.captureUntil(command, src, nil)
+	if err != nil {
+		return remoteFileError("upload", targetFile, stderr, err)
+	}
+
+	return nil
+}
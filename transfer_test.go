@@ -0,0 +1,47 @@
+package easyssh
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadFailureKeepsTarget(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "easyssh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	target := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(target, []byte("previous\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.Download("/missing", target); err == nil {
+		t.Fatal("Expected download of missing file to fail")
+	}
+	if content, err := ioutil.ReadFile(target); err != nil || string(content) != "previous\n" {
+		t.Errorf("Expected previous file to be kept, got %q (%v)", content, err)
+	}
+
+	if err := srv.WriteFile("/config", []byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Download("/config", target); err != nil {
+		t.Fatalf("Error downloading: %s", err)
+	}
+	if content, err := ioutil.ReadFile(target); err != nil || string(content) != "new\n" {
+		t.Errorf("Expected downloaded file, got %q (%v)", content, err)
+	}
+	if info, err := os.Stat(target); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode of previous file to be kept, got %v (%v)", info.Mode(), err)
+	}
+
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("Expected no temporary files to be left behind, got %d files", len(files))
+	}
+}
@@ -0,0 +1,54 @@
+package easyssh
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// Dial connects to addr from the remote machine, like "ssh -W" does, and
+// returns the connection tunneled through SSH. network needs to be "tcp",
+// "tcp4", "tcp6" or "unix". Any Go client library accepting a net.Conn or a
+// dial function can be pointed at hosts reachable from the remote machine
+// this way, without opening a local port.
+func (c *Client) Dial(network, addr string) (net.Conn, error) {
+	return c.DialContext(context.Background(), network, addr)
+}
+
+// DialContext works like Dial, but gives up once ctx is done. Its signature
+// matches the DialContext field of http.Transport and net.Dialer.
+func (c *Client) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := c.client.DialContext(ctx, network, addr)
+	if err != nil {
+		c.config.logf(LogError, "Error dialing %s via %s: %s", addr, c.config.address(), err)
+		return nil, err
+	}
+	c.config.logf(LogDebug2, "Dialed %s via %s", addr, c.config.address())
+	return conn, nil
+}
+
+// Listen asks the remote machine to listen on addr, like "ssh -R" does, and
+// returns a listener accepting the connections made to it. network needs to
+// be "tcp", "tcp4", "tcp6" or "unix". Binding to other addresses than the
+// loopback interface needs GatewayPorts to be enabled on the server.
+func (c *Client) Listen(network, addr string) (net.Listener, error) {
+	l, err := c.client.Listen(network, addr)
+	if err != nil {
+		c.config.logf(LogError, "Error listening on %s of %s: %s", addr, c.config.address(), err)
+		return nil, err
+	}
+	c.config.logf(LogDebug1, "Listening on %s of %s", l.Addr(), c.config.address())
+	return l, nil
+}
+
+// HTTPTransport returns an *http.Transport making all connections through the
+// remote machine, so an http.Client using it can reach internal APIs on or
+// behind it. Host names are resolved by the remote machine. Proxies configured
+// in the environment are ignored. The transport stops working once the client
+// is closed.
+func (c *Client) HTTPTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = c.DialContext
+	return transport
+}
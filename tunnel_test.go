@@ -0,0 +1,96 @@
+package easyssh
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientDial(t *testing.T) {
+	srv, client := connectTestServer(t)
+	defer srv.Close()
+	defer client.Close()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		conn.Write([]byte("echo: " + line))
+		conn.Close()
+	}()
+
+	conn, err := client.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.Write([]byte("hello\n"))
+	if reply, err := ioutil.ReadAll(conn); err != nil || string(reply) != "echo: hello\n" {
+		t.Errorf("Expected reply through tunnel, got %q (%v)", reply, err)
+	}
+}
+
+func TestClientListen(t *testing.T) {
+	srv, client := connectTestServer(t)
+	defer srv.Close()
+	defer client.Close()
+
+	l, err := client.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		conn.Write([]byte("hello from the tunnel\n"))
+		conn.Close()
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if reply, err := ioutil.ReadAll(conn); err != nil || string(reply) != "hello from the tunnel\n" {
+		t.Errorf("Expected data from reverse tunnel, got %q (%v)", reply, err)
+	}
+}
+
+func TestClientHTTPTransport(t *testing.T) {
+	srv, client := connectTestServer(t)
+	defer srv.Close()
+	defer client.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal " + r.URL.Path))
+	}))
+	defer api.Close()
+
+	transport := client.HTTPTransport()
+	defer transport.CloseIdleConnections()
+
+	resp, err := (&http.Client{Transport: transport}).Get(api.URL + "/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if body, _ := ioutil.ReadAll(resp.Body); string(body) != "internal /status" {
+		t.Errorf("Expected response through tunnel, got %q", body)
+	}
+}
@@ -0,0 +1,254 @@
+package easyssh
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var userNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*\$?$`)
+
+// UserOptions describes the account created by EnsureUser.
+type UserOptions struct {
+	// Home is the home directory of the user. If empty, the system default is
+	// used.
+	Home string
+	// Shell is the login shell of the user. If empty, the system default is
+	// used.
+	Shell string
+	// Comment is the GECOS field of the user (ex. the full name).
+	Comment string
+	// Groups are supplementary groups the user is added to. They need to exist.
+	Groups []string
+	// System creates a system account without a home directory.
+	System bool
+}
+
+func (opts UserOptions) validate() error {
+	for _, group := range opts.Groups {
+		if !userNameRegex.MatchString(group) {
+			return fmt.Errorf("Invalid group name: '%s'", group)
+		}
+	}
+	for _, value := range []string{opts.Home, opts.Shell, opts.Comment} {
+		if strings.ContainsAny(value, ":\r\n") {
+			return fmt.Errorf("Invalid user option: %q", value)
+		}
+	}
+	return nil
+}
+
+// userCommand returns a shell command creating the user name as described by
+// opts, or updating shell and groups if the user already exists.
+func userCommand(name string, opts UserOptions) string {
+	var create, update []string
+
+	if opts.System {
+		create = append(create, "-r")
+	} else {
+		create = append(create, "-m")
+	}
+	if opts.Home != "" {
+		create = append(create, "-d", shellQuote(opts.Home))
+	}
+	if opts.Comment != "" {
+		create = append(create, "-c", shellQuote(opts.Comment))
+	}
+	if opts.Shell != "" {
+		create = append(create, "-s", shellQuote(opts.Shell))
+		update = append(update, "-s", shellQuote(opts.Shell))
+	}
+	if len(opts.Groups) > 0 {
+		groups := shellQuote(strings.Join(opts.Groups, ","))
+		create = append(create, "-G", groups)
+		update = append(update, "-a", "-G", groups)
+	}
+
+	user := shellQuote(name)
+	command := "if id -u " + user + " >/dev/null 2>&1; then "
+	if len(update) > 0 {
+		command += "usermod " + strings.Join(update, " ") + " " + user
+	} else {
+		command += "true"
+	}
+	return command + "; else useradd " + strings.Join(create, " ") + " " + user + "; fi"
+}
+
+// EnsureUser creates the user name on the remote machine as described by opts.
+// If the user already exists, only its shell and supplementary groups are
+// updated. This needs root privileges (see Escalation).
+func (ssh_conf *MakeConfig) EnsureUser(name string, opts UserOptions) error {
+	if !userNameRegex.MatchString(name) {
+		return fmt.Errorf("Invalid user name: '%s'", name)
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	return ssh_conf.privileged(userCommand(name, opts))
+}
+
+// EnsureGroup creates the group name on the remote machine, unless it already
+// exists. This needs root privileges (see Escalation).
+func (ssh_conf *MakeConfig) EnsureGroup(name string) error {
+	if !userNameRegex.MatchString(name) {
+		return fmt.Errorf("Invalid group name: '%s'", name)
+	}
+
+	group := shellQuote(name)
+	return ssh_conf.privileged("getent group " + group + " >/dev/null || groupadd " + group)
+}
+
+// homeCommand returns a shell command setting $home to the home directory of
+// user, or of the user logged in if user is empty.
+func homeCommand(user string) string {
+	if user == "" {
+		return `home="$HOME"; `
+	}
+	return "home=$(getent passwd " + shellQuote(user) + " | cut -d: -f6); " +
+		`[ -n "$home" ] || { echo "No such user" >&2; exit 1; }; `
+}
+
+// authorizedKeyCommand returns a shell command adding line to the
+// authorized_keys file of user, unless a line containing match is already
+// there. If user is empty, the user logged in is used.
+func authorizedKeyCommand(user, line, match string) string {
+	command := "set -e; " + homeCommand(user) +
+		`mkdir -p "$home/.ssh"; chmod 700 "$home/.ssh"; ` +
+		`touch "$home/.ssh/authorized_keys"; chmod 600 "$home/.ssh/authorized_keys"; ` +
+		"grep -qF -- " + shellQuote(match) + ` "$home/.ssh/authorized_keys" || ` +
+		"echo " + shellQuote(line) + ` >> "$home/.ssh/authorized_keys"`
+
+	if user != "" {
+		command += "; chown -R " + shellQuote(user) + `: "$home/.ssh"`
+	}
+
+	return command
+}
+
+// removeAuthorizedKeyCommand returns a shell command removing all lines
+// containing match from the authorized_keys file of user. If user is empty,
+// the user logged in is used.
+func removeAuthorizedKeyCommand(user, match string) string {
+	command := "set -e; " + homeCommand(user) +
+		`f="$home/.ssh/authorized_keys"; [ -e "$f" ] || exit 0; umask 077; ` +
+		"{ grep -vF -- " + shellQuote(match) + ` "$f" || [ $? -eq 1 ]; } > "$f.tmp"; ` +
+		`mv -f -- "$f.tmp" "$f"`
+
+	if user != "" {
+		command += "; chown " + shellQuote(user) + `: "$f"`
+	}
+
+	return command
+}
+
+// AddAuthorizedKey adds pubkey (a line in authorized_keys format, ex.
+// "ssh-ed25519 AAAA... john@example.com") to the authorized_keys file of user,
+// unless the key is already there. If user is empty, the user logged in is
+// used. Adding keys for other users needs root privileges (see Escalation).
+func (ssh_conf *MakeConfig) AddAuthorizedKey(user, pubkey string) error {
+	if user != "" && !userNameRegex.MatchString(user) {
+		return fmt.Errorf("Invalid user name: '%s'", user)
+	}
+
+	line, match, err := parseAuthorizedKeyLine(pubkey)
+	if err != nil {
+		return err
+	}
+
+	if user == "" {
+		_, stderr, err := ssh_conf.capture(authorizedKeyCommand(user, line, match))
+		if err != nil {
+			return remoteError(stderr, err)
+		}
+		return nil
+	}
+
+	return ssh_conf.privileged(authorizedKeyCommand(user, line, match))
+}
+
+// parseAuthorizedKeyLine validates pubkey, a single line in authorized_keys
+// format, and returns it trimmed along with the key type and data, which
+// identify the key regardless of options and comment.
+func parseAuthorizedKeyLine(pubkey string) (line, match string, err error) {
+	line = strings.TrimSpace(pubkey)
+	key, _, _, rest, err := ssh.ParseAuthorizedKey([]byte(line))
+	if err != nil || len(rest) > 0 || strings.ContainsAny(line, "\r\n") {
+		return "", "", fmt.Errorf("Invalid public key: %q", pubkey)
+	}
+	return line, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))), nil
+}
+
+// AuthorizedKey is a key listed in an authorized_keys file.
+type AuthorizedKey struct {
+	Key     ssh.PublicKey
+	Comment string
+	// Options restrict the use of the key (ex. "from=\"10.0.0.0/8\"" or
+	// "no-port-forwarding").
+	Options []string
+}
+
+// ListAuthorizedKeys returns the keys in the authorized_keys file of user,
+// which is empty if the file does not exist. If user is empty, the user logged
+// in is used. Listing the keys of other users needs root privileges (see
+// Escalation). Lines which cannot be parsed are skipped.
+func (ssh_conf *MakeConfig) ListAuthorizedKeys(user string) ([]AuthorizedKey, error) {
+	if user != "" && !userNameRegex.MatchString(user) {
+		return nil, fmt.Errorf("Invalid user name: '%s'", user)
+	}
+
+	command := homeCommand(user) + `cat "$home/.ssh/authorized_keys" 2>/dev/null || true`
+	var stdout, stderr []byte
+	var err error
+	if user == "" {
+		stdout, stderr, err = ssh_conf.capture(command)
+	} else {
+		stdout, stderr, err = ssh_conf.privilegedCapture(command)
+	}
+	if err != nil {
+		return nil, remoteError(stderr, err)
+	}
+
+	return parseAuthorizedKeys(stdout), nil
+}
+
+// parseAuthorizedKeys returns the keys of the authorized_keys file data.
+func parseAuthorizedKeys(data []byte) []AuthorizedKey {
+	keys := []AuthorizedKey{}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		key, comment, options, _, err := ssh.ParseAuthorizedKey(line)
+		if err != nil {
+			continue
+		}
+		keys = append(keys, AuthorizedKey{Key: key, Comment: comment, Options: options})
+	}
+	return keys
+}
+
+// RemoveAuthorizedKey removes pubkey (a line in authorized_keys format, whose
+// options and comment are ignored) from the authorized_keys file of user. If
+// user is empty, the user logged in is used. Removing keys of other users
+// needs root privileges (see Escalation).
+func (ssh_conf *MakeConfig) RemoveAuthorizedKey(user, pubkey string) error {
+	if user != "" && !userNameRegex.MatchString(user) {
+		return fmt.Errorf("Invalid user name: '%s'", user)
+	}
+
+	_, match, err := parseAuthorizedKeyLine(pubkey)
+	if err != nil {
+		return err
+	}
+
+	if user == "" {
+		_, stderr, err := ssh_conf.capture(removeAuthorizedKeyCommand(user, match))
+		if err != nil {
+			return remoteError(stderr, err)
+		}
+		return nil
+	}
+
+	return ssh_conf.privileged(removeAuthorizedKeyCommand(user, match))
+}
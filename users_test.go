@@ -0,0 +1,138 @@
+package easyssh
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/hypersleep/easyssh.v0/testserver"
+)
+
+func TestUserCommand(t *testing.T) {
+	command := userCommand("deploy", UserOptions{Shell: "/bin/bash", Groups: []string{"docker", "adm"}})
+	expected := "if id -u 'deploy' >/dev/null 2>&1; then usermod -s '/bin/bash' -a -G 'docker,adm' 'deploy'; " +
+		"else useradd -m -s '/bin/bash' -G 'docker,adm' 'deploy'; fi"
+	if command != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, command)
+	}
+
+	command = userCommand("svc", UserOptions{System: true})
+	expected = "if id -u 'svc' >/dev/null 2>&1; then true; else useradd -r 'svc'; fi"
+	if command != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, command)
+	}
+}
+
+func TestEnsureUserValidation(t *testing.T) {
+	cfg := &MakeConfig{}
+	if err := cfg.EnsureUser("rm -rf /", UserOptions{}); err == nil {
+		t.Errorf("Expected error for invalid user name")
+	}
+	if err := cfg.EnsureUser("deploy", UserOptions{Groups: []string{"a;b"}}); err == nil {
+		t.Errorf("Expected error for invalid group name")
+	}
+	if err := cfg.EnsureGroup("$(reboot)"); err == nil {
+		t.Errorf("Expected error for invalid group name")
+	}
+}
+
+func TestAddAuthorizedKeyValidation(t *testing.T) {
+	cfg := &MakeConfig{}
+	if err := cfg.AddAuthorizedKey("deploy", "not a key"); err == nil {
+		t.Errorf("Expected error for invalid public key")
+	}
+
+	key := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGgMHBBcXeDp1Ll/Y7G9ELSsBgIEaTMs1bpWp3SVtrNs test\nssh-rsa AAAA"
+	if err := cfg.AddAuthorizedKey("deploy", key); err == nil {
+		t.Errorf("Expected error for multiple lines")
+	}
+}
+
+func TestAuthorizedKeyCommand(t *testing.T) {
+	command := authorizedKeyCommand("deploy", "ssh-ed25519 AAAA test", "ssh-ed25519 AAAA")
+	if !strings.Contains(command, "grep -qF -- 'ssh-ed25519 AAAA' ") {
+		t.Errorf("Expected key to be matched without comment, got '%s'", command)
+	}
+	if !strings.HasSuffix(command, `chown -R 'deploy': "$home/.ssh"`) {
+		t.Errorf("Expected .ssh to be owned by user, got '%s'", command)
+	}
+	if strings.Contains(authorizedKeyCommand("", "ssh-ed25519 AAAA", "ssh-ed25519 AAAA"), "chown") {
+		t.Errorf("Expected no chown for the user logged in")
+	}
+}
+
+func TestAuthorizedKeyCommandsInShell(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("No shell available")
+	}
+	home, err := ioutil.TempDir("", "easyssh")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(home)
+
+	run := func(command string) {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Env = append(os.Environ(), "HOME="+home)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Error running '%s': %s (%s)", command, err, out)
+		}
+	}
+
+	first := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(newTestSigner(t).PublicKey())))
+	second := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(newTestSigner(t).PublicKey())))
+	run(authorizedKeyCommand("", first+" alice", first))
+	run(authorizedKeyCommand("", `no-pty `+second+" bob", second))
+	run(authorizedKeyCommand("", first+" alice again", first))
+
+	file := filepath.Join(home, ".ssh", "authorized_keys")
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Error reading authorized_keys: %s", err)
+	}
+	keys := parseAuthorizedKeys(content)
+	if len(keys) != 2 || keys[0].Comment != "alice" || keys[1].Comment != "bob" ||
+		len(keys[1].Options) != 1 || keys[1].Options[0] != "no-pty" {
+		t.Fatalf("Expected keys of alice and bob, got %q", content)
+	}
+
+	run(removeAuthorizedKeyCommand("", second))
+	if content, _ = ioutil.ReadFile(file); string(content) != first+" alice\n" {
+		t.Errorf("Expected only the key of alice to be left, got %q", content)
+	}
+	run(removeAuthorizedKeyCommand("", first))
+	if content, _ = ioutil.ReadFile(file); len(content) != 0 {
+		t.Errorf("Expected no keys to be left, got %q", content)
+	}
+	if info, err := os.Stat(file); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected authorized_keys to keep mode 0600, got %v (%v)", info.Mode(), err)
+	}
+
+	os.Remove(file)
+	run(removeAuthorizedKeyCommand("", first))
+}
+
+func TestListAuthorizedKeys(t *testing.T) {
+	srv, cfg := echoTestServer(t)
+	defer srv.Close()
+	key := string(ssh.MarshalAuthorizedKey(newTestSigner(t).PublicKey()))
+	srv.HandleDefault(testserver.Reply("# managed by easyssh\n"+strings.TrimSpace(key)+" deploy@ci\ngarbage\n", 0))
+
+	keys, err := cfg.ListAuthorizedKeys("")
+	if err != nil {
+		t.Fatalf("Error listing keys: %s", err)
+	}
+	if len(keys) != 1 || keys[0].Comment != "deploy@ci" || string(ssh.MarshalAuthorizedKey(keys[0].Key)) != key {
+		t.Errorf("Expected the key of deploy@ci, got %v", keys)
+	}
+	if _, err := cfg.ListAuthorizedKeys("$(reboot)"); err == nil {
+		t.Errorf("Expected error for invalid user name")
+	}
+	if err := cfg.RemoveAuthorizedKey("", "not a key"); err == nil {
+		t.Errorf("Expected error for invalid public key")
+	}
+}
@@ -0,0 +1,190 @@
+package easyssh
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+// ErrStepTimeout is returned for a workflow step which did not finish within
+// its Timeout.
+var ErrStepTimeout = errors.New("Workflow step timed out")
+
+// Step is a single step of a Workflow. Do performs the step on host and should
+// give up once ctx is done. If Timeout is set, the step fails with
+// ErrStepTimeout once it is exceeded. If Retry is set, failed attempts are
+// retried according to it. Rollback undoes the step if a later step fails.
+type Step struct {
+	Name     string
+	Do       func(ctx context.Context, host *MakeConfig) error
+	Timeout  time.Duration
+	Retry    *RetryPolicy
+	Rollback func(host *MakeConfig) error
+}
+
+// RunStep returns a Step running command, which fails if the command fails.
+func RunStep(name, command string) Step {
+	return Step{Name: name, Do: func(ctx context.Context, host *MakeConfig) error {
+		_, stderr, err := host.captureUntil(command, nil, ctx.Done())
+		if err != nil {
+			return remoteError(stderr, err)
+		}
+		return nil
+	}}
+}
+
+// CheckStep returns a Step verifying the state of the host by running command,
+// which needs to succeed and must not change anything. It is retried three
+// times by default, as checks often wait for services to come up.
+func CheckStep(name, command string) Step {
+	step := RunStep(name, command)
+	step.Retry = &RetryPolicy{MaxAttempts: 3}
+	return step
+}
+
+// UploadStep returns a Step uploading the local file source to target.
+func UploadStep(name, source, target string) Step {
+	return Step{Name: name, Do: func(ctx context.Context, host *MakeConfig) error {
+		return host.Upload(source, target)
+	}}
+}
+
+// SyncStep returns a Step synchronizing the local directory source to target
+// (see Sync).
+func SyncStep(name, source, target string, opts SyncOptions) Step {
+	return Step{Name: name, Do: func(ctx context.Context, host *MakeConfig) error {
+		_, err := host.Sync(source, target, opts)
+		return err
+	}}
+}
+
+// TemplateStep returns a Step rendering the text/template text with data and
+// writing the result to the remote file target.
+func TemplateStep(name, text string, data interface{}, target string) Step {
+	return Step{Name: name, Do: func(ctx context.Context, host *MakeConfig) error {
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return fmt.Errorf("Error parsing template: %s", err)
+		}
+
+		var content bytes.Buffer
+		if err := tmpl.Execute(&content, data); err != nil {
+			return fmt.Errorf("Error rendering template: %s", err)
+		}
+
+		return host.writeRemoteFile(target, content.String())
+	}}
+}
+
+// Workflow is an ordered list of steps run against a host or all hosts of a
+// Group, like a minimal deployment. If a step fails, the remaining ones are
+// skipped and the completed ones rolled back in reverse order.
+type Workflow struct {
+	Steps []Step
+}
+
+// StepResult is the outcome of a single step of a Workflow.
+type StepResult struct {
+	Name        string
+	Attempts    int
+	Duration    time.Duration
+	Err         error
+	RolledBack  bool
+	RollbackErr error
+}
+
+// WorkflowReport is the outcome of a Workflow on a host. Steps holds the
+// results of all steps which were run. Err is the error of the failed step.
+type WorkflowReport struct {
+	Steps []StepResult
+	Err   error
+}
+
+// Run runs the workflow on host.
+func (w *Workflow) Run(host *MakeConfig) *WorkflowReport {
+	report := &WorkflowReport{}
+
+	for _, step := range w.Steps {
+		result := host.runStep(step)
+		report.Steps = append(report.Steps, result)
+		if result.Err == nil {
+			continue
+		}
+
+		report.Err = fmt.Errorf("Step '%s' failed: %s", step.Name, result.Err)
+		host.logf(LogError, "Workflow step '%s' failed on %s: %s", step.Name, host.address(), result.Err)
+
+		for i := len(report.Steps) - 2; i >= 0; i-- {
+			done := w.Steps[i]
+			if done.Rollback == nil {
+				continue
+			}
+			report.Steps[i].RolledBack = true
+			report.Steps[i].RollbackErr = done.Rollback(host)
+		}
+		break
+	}
+
+	return report
+}
+
+// RunWorkflow runs w on all hosts of the group in parallel and returns the
+// results in the order of Hosts, with their Workflow set.
+func (g *Group) RunWorkflow(w *Workflow) Results {
+	defer g.shareJumpHosts()()
+
+	return g.forEach(func(host *MakeConfig) HostResult {
+		report := w.Run(host)
+		return HostResult{Host: host, Labels: host.Labels, Workflow: report, Err: report.Err}
+	})
+}
+
+// runs step on MakeConfig, honoring its Timeout and Retry policy
+func (ssh_conf *MakeConfig) runStep(step Step) StepResult {
+	result := StepResult{Name: step.Name}
+	start := ssh_conf.clock().Now()
+	ssh_conf.logf(LogVerbose, "Running workflow step '%s' on %s", step.Name, ssh_conf.address())
+
+	attempt := func() error {
+		result.Attempts++
+		return ssh_conf.runStepOnce(step)
+	}
+
+	if step.Retry != nil {
+		retryAll := func(error) bool { return true }
+		result.Err = step.Retry.do(context.Background(), ssh_conf.clock(), ssh_conf.random(), retryAll, attempt)
+	} else {
+		result.Err = attempt()
+	}
+
+	result.Duration = ssh_conf.clock().Now().Sub(start)
+	return result
+}
+
+// runs a single attempt of step, cancelling it once its Timeout is exceeded
+func (ssh_conf *MakeConfig) runStepOnce(step Step) error {
+	if step.Do == nil {
+		return fmt.Errorf("Step '%s' has nothing to do", step.Name)
+	}
+	if step.Timeout <= 0 {
+		return step.Do(context.Background(), ssh_conf)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	result := make(chan error, 1)
+	ssh_conf.spawn(func() {
+		result <- step.Do(ctx, ssh_conf)
+	})
+
+	select {
+	case err := <-result:
+		return err
+	case <-ssh_conf.clock().After(step.Timeout):
+		return ErrStepTimeout
+	}
+}
@@ -0,0 +1,86 @@
+package easyssh
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWorkflowRollback(t *testing.T) {
+	var log []string
+	step := func(name string, err error) Step {
+		return Step{
+			Name: name,
+			Do: func(ctx context.Context, host *MakeConfig) error {
+				log = append(log, "do "+name)
+				return err
+			},
+			Rollback: func(host *MakeConfig) error {
+				log = append(log, "undo "+name)
+				return nil
+			},
+		}
+	}
+
+	w := &Workflow{Steps: []Step{
+		step("a", nil),
+		{Name: "b", Do: func(ctx context.Context, host *MakeConfig) error { return nil }},
+		step("c", nil),
+		step("d", errors.New("boom")),
+		step("e", nil),
+	}}
+	report := w.Run(&MakeConfig{})
+
+	expected := []string{"do a", "do c", "do d", "undo c", "undo a"}
+	if !reflect.DeepEqual(log, expected) {
+		t.Errorf("Expected %v, got %v", expected, log)
+	}
+	if report.Err == nil || len(report.Steps) != 4 {
+		t.Errorf("Expected failure after 4 steps, got %v with %d steps", report.Err, len(report.Steps))
+	}
+	if !report.Steps[0].RolledBack || report.Steps[1].RolledBack || report.Steps[3].RolledBack {
+		t.Errorf("Unexpected rollbacks: %+v", report.Steps)
+	}
+}
+
+func TestWorkflowRetryAndTimeout(t *testing.T) {
+	failures := 2
+	w := &Workflow{Steps: []Step{
+		{
+			Name:  "flaky",
+			Retry: &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond},
+			Do: func(ctx context.Context, host *MakeConfig) error {
+				if failures > 0 {
+					failures--
+					return errors.New("not yet")
+				}
+				return nil
+			},
+		},
+		{
+			Name:    "slow",
+			Timeout: 10 * time.Millisecond,
+			Do: func(ctx context.Context, host *MakeConfig) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+	}}
+
+	cfg := &MakeConfig{}
+	report := w.Run(cfg)
+	if report.Steps[0].Err != nil || report.Steps[0].Attempts != 3 {
+		t.Errorf("Expected flaky step to succeed on the third attempt, got %+v", report.Steps[0])
+	}
+	if report.Steps[1].Err != ErrStepTimeout {
+		t.Errorf("Expected slow step to time out, got %v", report.Steps[1].Err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := cfg.WaitIdle(ctx); err != nil {
+		t.Errorf("Expected cancelled step to exit, got %s", err)
+	}
+}